@@ -0,0 +1,70 @@
+// radosgw-migrate moves a user, its buckets and their objects from a cluster to another.
+//
+// The admin credentials are read from the environment:
+// SOURCE_ACCESS, SOURCE_SECRET, TARGET_ACCESS and TARGET_SECRET.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/QuentinPerez/go-radosgw/pkg/api"
+	"github.com/QuentinPerez/go-radosgw/pkg/migrate"
+)
+
+func main() {
+	var (
+		uid          = flag.String("uid", "", "the user to migrate")
+		source       = flag.String("source", "", "the admin endpoint of the source cluster, e.g. http://rgw1:7480")
+		target       = flag.String("target", "", "the admin endpoint of the target cluster")
+		sourceS3     = flag.String("source-s3", "", "the S3 endpoint of the source cluster, host[:port]")
+		targetS3     = flag.String("target-s3", "", "the S3 endpoint of the target cluster, host[:port]")
+		secure       = flag.Bool("secure", false, "use https to reach the S3 endpoints")
+		checkpoint   = flag.String("checkpoint", "", "the file recording the copied objects, defaults to <uid>.checkpoint.json")
+		removeSource = flag.Bool("remove-source", false, "remove the user and its data from the source once every bucket is verified")
+	)
+	flag.Parse()
+	if *uid == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *checkpoint == "" {
+		*checkpoint = *uid + ".checkpoint.json"
+	}
+
+	sourceAPI, err := radosAPI.New(*source, os.Getenv("SOURCE_ACCESS"), os.Getenv("SOURCE_SECRET"))
+	if err != nil {
+		log.Fatalf("source: %v", err)
+	}
+	targetAPI, err := radosAPI.New(*target, os.Getenv("TARGET_ACCESS"), os.Getenv("TARGET_SECRET"))
+	if err != nil {
+		log.Fatalf("target: %v", err)
+	}
+
+	report, err := migrate.Run(migrate.Config{
+		UID:          *uid,
+		Source:       sourceAPI,
+		Target:       targetAPI,
+		SourceS3:     *sourceS3,
+		TargetS3:     *targetS3,
+		Secure:       *secure,
+		Checkpoint:   *checkpoint,
+		RemoveSource: *removeSource,
+		Progress: func(p migrate.Progress) {
+			fmt.Printf("%s: %d/%d %s\n", p.Bucket, p.Copied, p.Total, p.Object)
+		},
+	})
+	if report != nil {
+		for _, bucket := range report.Buckets {
+			fmt.Printf("%s: %d objects copied, %d on the source, %d on the target\n", bucket.Bucket, bucket.Copied, bucket.SourceObjects, bucket.TargetObjects)
+		}
+	}
+	if err != nil {
+		log.Fatalf("%s: %v, run the same command again to resume", *uid, err)
+	}
+	if report.SourceRemoved {
+		fmt.Printf("%s removed from the source\n", *uid)
+	}
+}
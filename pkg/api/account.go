@@ -0,0 +1,154 @@
+package radosAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+
+	"github.com/QuentinPerez/go-encodeUrl"
+)
+
+// AccountConfig account request
+type AccountConfig struct {
+	ID            string `url:"id,ifStringIsNotEmpty"`        // The account ID (RGW followed by 17 digits), generated if not specified on creation
+	Name          string `url:"name,ifStringIsNotEmpty"`      // The name of the account
+	Email         string `url:"email,ifStringIsNotEmpty"`     // The email address associated with the account
+	Tenant        string `url:"tenant,ifStringIsNotEmpty"`    // The tenant of the account
+	MaxUsers      *int   `url:"max-users,itoaIfNotNil"`       // The maximum number of users in the account. A negative value removes the limit
+	MaxRoles      *int   `url:"max-roles,itoaIfNotNil"`       // The maximum number of roles in the account. A negative value removes the limit
+	MaxGroups     *int   `url:"max-groups,itoaIfNotNil"`      // The maximum number of groups in the account. A negative value removes the limit
+	MaxBuckets    *int   `url:"max-buckets,itoaIfNotNil"`     // The maximum number of buckets owned by the account. A negative value removes the limit
+	MaxAccessKeys *int   `url:"max-access-keys,itoaIfNotNil"` // The maximum number of access keys per user. A negative value removes the limit
+}
+
+// AccountQuotaConfig account quota request
+type AccountQuotaConfig struct {
+	ID         string `url:"id,ifStringIsNotEmpty"`         // The account ID
+	QuotaType  string `url:"quota-type,ifStringIsNotEmpty"` // The scope of the quota. The options are account and bucket.
+	MaxSize    *int64 `url:"max-size,int64IfNotNil"`        // The maximum number of bytes. A negative value disables this setting
+	MaxObjects *int64 `url:"max-objects,int64IfNotNil"`     // The maximum number of objects. A negative value disables this setting
+	Enabled    *bool  `url:"enabled,boolIfNotNil"`          // The enabled option enables the quota
+}
+
+func (api *API) accountCall(operation, verb string, conf interface{}, ret interface{}, sub ...string) error {
+	var (
+		values = url.Values{}
+		errs   []error
+	)
+
+	if err := api.requireFeature(FeatureAccounts); err != nil {
+		return err
+	}
+	values, errs = encurl.Translate(conf)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	values.Add("format", "json")
+	body, _, err := api.call(operation, verb, "/account", values, true, sub...)
+	if err != nil {
+		return err
+	}
+	if ret == nil {
+		return nil
+	}
+	return json.Unmarshal(body, ret)
+}
+
+// CreateAccount creates a new account. Users created with AccountID belong to it.
+//
+// !! caps: accounts=write !!
+//
+// @ID
+// @Name
+// @Email
+// @Tenant
+// @MaxUsers
+// @MaxRoles
+// @MaxGroups
+// @MaxBuckets
+// @MaxAccessKeys
+func (api *API) CreateAccount(conf AccountConfig) (*Account, error) {
+	ret := &Account{}
+
+	if conf.Name == "" {
+		return nil, errors.New("Name field is required")
+	}
+	if err := api.accountCall("CreateAccount", "POST", conf, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetAccount gets account information, by ID or by Name and Tenant.
+//
+// !! caps: accounts=read !!
+//
+// @ID
+// @Name
+// @Tenant
+func (api *API) GetAccount(conf AccountConfig) (*Account, error) {
+	ret := &Account{}
+
+	if conf.ID == "" && conf.Name == "" {
+		return nil, errors.New("ID or Name field is required")
+	}
+	if err := api.accountCall("GetAccount", "GET", AccountConfig{ID: conf.ID, Name: conf.Name, Tenant: conf.Tenant}, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// UpdateAccount modifies an account
+//
+// !! caps: accounts=write !!
+//
+// @ID
+// @Name
+// @Email
+// @MaxUsers
+// @MaxRoles
+// @MaxGroups
+// @MaxBuckets
+// @MaxAccessKeys
+func (api *API) UpdateAccount(conf AccountConfig) (*Account, error) {
+	ret := &Account{}
+
+	if conf.ID == "" {
+		return nil, errors.New("ID field is required")
+	}
+	if err := api.accountCall("UpdateAccount", "PUT", conf, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// RemoveAccount removes an existing account. The account must not own users, roles or buckets anymore.
+//
+// !! caps: accounts=write !!
+//
+// @ID
+func (api *API) RemoveAccount(conf AccountConfig) error {
+	if conf.ID == "" {
+		return errors.New("ID field is required")
+	}
+	return api.accountCall("RemoveAccount", "DELETE", AccountConfig{ID: conf.ID}, nil)
+}
+
+// UpdateAccountQuota updates account's quotas, applied to the whole account or to each of its buckets
+//
+// !! caps: accounts=write !!
+//
+// @ID
+// @QuotaType [account,bucket]
+// @MaxSize
+// @MaxObjects
+// @Enabled
+func (api *API) UpdateAccountQuota(conf AccountQuotaConfig) error {
+	if conf.ID == "" {
+		return errors.New("ID field is required")
+	}
+	if conf.QuotaType != "account" && conf.QuotaType != "bucket" {
+		return errors.New("QuotaType field should be account or bucket")
+	}
+	return api.accountCall("UpdateAccountQuota", "PUT", conf, nil, "quota")
+}
@@ -1,27 +1,50 @@
 package radosAPI
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
 	"time"
 
 	"github.com/smartystreets/go-aws-auth"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // API contains fields to communicate with the rados-gateway
 type API struct {
-	host      string
+	endpoints *endpointPool // Shared with the copies returned by WithContext
 	accessKey string
 	secretKey string
 	prefix    string
 	client    *http.Client
+
+	sessionToken string
+	expiration   time.Time
+
+	probe *probeState // Shared with the copies returned by WithContext
+
+	logger  Logger
+	tracer  trace.TracerProvider
+	metrics Metrics
+	limits  *limits
+	ctx     context.Context
+
+	safety       *SafetyPolicy
+	confirmation string // The purge confirmed by WithConfirmation
+	plan         *Plan  // Record the mutating requests instead of sending them
 }
 
-// New returns client for Ceph RADOS Gateway
+// New returns client for Ceph RADOS Gateway.
+// host may be a comma separated list of gateways, see SetBalancer.
 func New(host, accessKey, secretKey string, adminPrefix ...string) (*API, error) {
 	return NewWithClient(&http.Client{}, host, accessKey, secretKey, adminPrefix...)
 }
@@ -34,22 +57,67 @@ func NewWithClient(client *http.Client, host, accessKey, secretKey string, admin
 	if host == "" || accessKey == "" || secretKey == "" {
 		return nil, fmt.Errorf("host, accessKey, secretKey must be not nil")
 	}
-	return &API{host, accessKey, secretKey, prefix, client}, nil
+	endpoints, err := newEndpointPool(host)
+	if err != nil {
+		return nil, err
+	}
+	return &API{
+		endpoints: endpoints,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		prefix:    prefix,
+		client:    client,
+		probe:     &probeState{},
+	}, nil
+}
+
+// WithContext returns a copy of api sending its requests with ctx, to cancel them
+// or to attach them to the trace of the caller
+func (api *API) WithContext(ctx context.Context) *API {
+	clone := *api
+	clone.ctx = ctx
+	return &clone
+}
+
+func (api *API) context() context.Context {
+	if api.ctx == nil {
+		return context.Background()
+	}
+	return api.ctx
+}
+
+// NewFromCredentials returns client for Ceph RADOS Gateway authenticated with temporary credentials,
+// as returned by AssumeRole, AssumeRoleWithWebIdentity or GetSessionToken
+func NewFromCredentials(host string, creds Credentials, adminPrefix ...string) (*API, error) {
+	api, err := New(host, creds.AccessKeyID, creds.SecretAccessKey, adminPrefix...)
+	if err != nil {
+		return nil, err
+	}
+	api.sessionToken = creds.SessionToken
+	api.expiration = creds.Expiration
+	return api, nil
 }
 
-func (api *API) makeRequest(verb, url string) (body []byte, statusCode int, err error) {
+func (api *API) makeRequest(ctx context.Context, spec request, url string) (body []byte, statusCode int, err error) {
 	var apiErr apiError
 
-	// fmt.Printf("URL [%v]: %v\n", verb, url)
-	req, err := http.NewRequest(verb, url, nil)
+	req, err := http.NewRequestWithContext(ctx, spec.verb, url, bytes.NewReader(spec.payload))
 	if err != nil {
 		return
 	}
-	awsauth.SignS3(req, awsauth.Credentials{
-		AccessKeyID:     api.accessKey,
-		SecretAccessKey: api.secretKey,
-		Expiration:      time.Now().Add(1 * time.Minute)},
-	)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	if !spec.anonymous {
+		if !api.expiration.IsZero() && time.Now().After(api.expiration) {
+			err = errors.New("session token expired")
+			return
+		}
+		awsauth.SignS3(req, awsauth.Credentials{
+			AccessKeyID:     api.accessKey,
+			SecretAccessKey: api.secretKey,
+			SecurityToken:   api.sessionToken,
+			Expiration:      time.Now().Add(1 * time.Minute)},
+		)
+	}
 	resp, err := api.client.Do(req)
 	if err != nil {
 		return
@@ -58,6 +126,15 @@ func (api *API) makeRequest(verb, url string) (body []byte, statusCode int, err
 		defer resp.Body.Close()
 	}
 	statusCode = resp.StatusCode
+	if spec.header != nil {
+		for k, v := range resp.Header {
+			spec.header[k] = v
+		}
+	}
+	if spec.decode != nil && statusCode >= 200 && statusCode <= 299 {
+		err = spec.decode(resp.Body)
+		return
+	}
 	body, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return
@@ -68,17 +145,82 @@ func (api *API) makeRequest(verb, url string) (body []byte, statusCode int, err
 	return
 }
 
-func (api *API) call(verb, route string, args url.Values, usePrefix bool, sub ...string) (body []byte, statusCode int, err error) {
-	subreq := ""
+// errorCode returns the code of a JSON or XML error response, if any
+func errorCode(body []byte) string {
+	var (
+		apiErr apiError
+		iamErr iamError
+	)
+
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Code != "" {
+		return apiErr.Code
+	}
+	if xml.Unmarshal(body, &iamErr) == nil {
+		if iamErr.Nested != "" {
+			return iamErr.Nested
+		}
+		return iamErr.Code
+	}
+	return ""
+}
+
+// request describes a request to the gateway, most of them are built by call
+type request struct {
+	operation string // The API method sending the request, such as CreateUser
+	verb      string
+	route     string
+	args      url.Values
+	sub       string // The subresource, sent before args
+	usePrefix bool   // Prepend the admin prefix to route
+	anonymous bool   // Don't sign the request
+	payload   []byte
+	header    http.Header           // Receives the headers of the response, if not nil
+	decode    func(io.Reader) error // Reads the body of a successful response instead of returning it
+}
+
+func (api *API) call(operation, verb, route string, args url.Values, usePrefix bool, sub ...string) (body []byte, statusCode int, err error) {
+	req := request{operation: operation, verb: verb, route: route, args: args, usePrefix: usePrefix}
 	if len(sub) > 0 {
-		subreq = fmt.Sprintf("%s&", sub[0])
+		req.sub = sub[0]
+	}
+	return api.do(req)
+}
+
+func (api *API) do(req request) (body []byte, statusCode int, err error) {
+	subreq := ""
+	if req.sub != "" {
+		subreq = fmt.Sprintf("%s&", req.sub)
 	}
-	if usePrefix {
+	route := req.route
+	if req.usePrefix {
 		route = fmt.Sprintf("/%s%s", api.prefix, route)
 	}
-	body, statusCode, err = api.makeRequest(verb, fmt.Sprintf("%v%v?%v%s", api.host, route, subreq, args.Encode()))
-	if statusCode != 200 {
+	if api.plan != nil && isMutation(req) {
+		return api.planRequest(req, route)
+	}
+	release, err := api.acquire(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer release()
+	ctx, span := api.startSpan(req, route)
+	if api.metrics != nil {
+		api.metrics.RequestStarted(req.operation)
+	}
+	start := time.Now()
+	body, statusCode, host, err := api.send(ctx, req, fmt.Sprintf("%v?%v%s", route, subreq, req.args.Encode()))
+	if statusCode < 200 || statusCode > 299 {
 		err = fmt.Errorf("[%v]: %v", statusCode, err)
 	}
+	latency := time.Since(start)
+	code := ""
+	if err != nil {
+		code = errorCode(body)
+	}
+	api.logRequest(req, host, route, statusCode, latency, code, err)
+	endSpan(span, statusCode, code, err)
+	if api.metrics != nil {
+		api.metrics.RequestDone(req.operation, statusCode, code, latency, err)
+	}
 	return
 }
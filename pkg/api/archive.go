@@ -0,0 +1,376 @@
+package radosAPI
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ArchiveVersion is the version of the archives written by WriteArchive
+const ArchiveVersion = 1
+
+// Archive formats
+const (
+	ArchiveJSON = "json"
+	ArchiveYAML = "yaml"
+)
+
+// Conflict policies, applied by ImportUsers when a user already exists
+const (
+	ConflictSkip      = "skip"      // Keep the existing user
+	ConflictOverwrite = "overwrite" // Replace the settings, keys, subusers, caps and quotas of the existing user
+	ConflictFail      = "fail"      // Stop the import
+)
+
+// Archive represents users exported by ExportUsers
+type Archive struct {
+	Version   int            `json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	Users     []ArchivedUser `json:"users"`
+}
+
+// ArchivedUser represents a user and its quotas
+type ArchivedUser struct {
+	User   User    `json:"user"`
+	Quotas *Quotas `json:"quotas,omitempty"`
+}
+
+// ArchiveProgress reports the progress of ExportUsers and ImportUsers
+type ArchiveProgress struct {
+	Done   int    // The number of users processed, including this one
+	Total  int    // The number of users to process
+	UID    string // The user processed
+	Action string // exported, created, overwritten, skipped or failed
+	Err    error  // The error of a failed user
+}
+
+// ExportConfig export request
+type ExportConfig struct {
+	UIDs     []string              // The users to export, all of them if empty
+	Progress func(ArchiveProgress) // Called after each user
+}
+
+// ImportConfig import request
+type ImportConfig struct {
+	Conflict string                // The conflict policy: skip (default), overwrite or fail
+	Progress func(ArchiveProgress) // Called after each user
+}
+
+// ImportReport represents the outcome of ImportUsers
+type ImportReport struct {
+	Created     []string
+	Overwritten []string
+	Skipped     []string
+	Failed      map[string]error
+}
+
+// ExportUsers reads users with their keys, subusers, caps and quotas.
+//
+// !! caps: users=read, metadata=read !!
+//
+// @UIDs
+// @Progress
+func (api *API) ExportUsers(conf ExportConfig) (*Archive, error) {
+	uids := conf.UIDs
+
+	if len(uids) == 0 {
+		var err error
+		if uids, err = api.GetUIDs(); err != nil {
+			return nil, err
+		}
+	}
+	ret := &Archive{Version: ArchiveVersion, CreatedAt: time.Now().UTC()}
+	for idx, uid := range uids {
+		user, err := api.GetUser(uid)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", uid, err)
+		}
+		quotas, err := api.GetQuotas(QuotaConfig{UID: uid})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", uid, err)
+		}
+		ret.Users = append(ret.Users, ArchivedUser{User: *user, Quotas: quotas})
+		if conf.Progress != nil {
+			conf.Progress(ArchiveProgress{Done: idx + 1, Total: len(uids), UID: uid, Action: "exported"})
+		}
+	}
+	return ret, nil
+}
+
+// WriteArchive writes archive to w, format is json or yaml
+func WriteArchive(w io.Writer, archive *Archive, format string) error {
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch format {
+	case ArchiveJSON:
+		_, err = w.Write(append(data, '\n'))
+		return err
+	case ArchiveYAML:
+		// YAML is written from the JSON document to keep the same keys
+		var document interface{}
+		if err = yaml.Unmarshal(data, &document); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		if err = enc.Encode(document); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	return fmt.Errorf("unknown archive format %q", format)
+}
+
+// ReadArchive reads an archive written by WriteArchive, in either format
+func ReadArchive(r io.Reader) (*Archive, error) {
+	ret := &Archive{}
+	buffered := bufio.NewReader(r)
+
+	first, err := peekNonSpace(buffered)
+	if err != nil {
+		return nil, err
+	}
+	if first == '{' {
+		err = json.NewDecoder(buffered).Decode(ret)
+	} else {
+		var document interface{}
+		if err = yaml.NewDecoder(buffered).Decode(&document); err != nil {
+			return nil, err
+		}
+		var data []byte
+		if data, err = json.Marshal(document); err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, ret)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if ret.Version != ArchiveVersion {
+		return nil, fmt.Errorf("unsupported archive version %d", ret.Version)
+	}
+	return ret, nil
+}
+
+// ImportUsers recreates the users of archive with their keys, subusers, caps and quotas.
+// A user failing to import is reported in ImportReport.Failed and the import goes on,
+// unless it already exists and the conflict policy is fail.
+//
+// !! caps: users=write !!
+//
+// @Conflict [skip,overwrite,fail]
+// @Progress
+func (api *API) ImportUsers(archive *Archive, conf ImportConfig) (*ImportReport, error) {
+	ret := &ImportReport{Failed: map[string]error{}}
+
+	switch conf.Conflict {
+	case "":
+		conf.Conflict = ConflictSkip
+	case ConflictSkip, ConflictOverwrite, ConflictFail:
+	default:
+		return nil, errors.New("Conflict field should be skip, overwrite or fail")
+	}
+	for idx, archived := range archive.Users {
+		var (
+			uid    = archived.User.UserID
+			action string
+			err    error
+		)
+
+		current, errGet := api.GetUser(uid)
+		switch {
+		case errGet != nil && !strings.Contains(errGet.Error(), "NoSuchUser"):
+			err = errGet
+		case errGet != nil:
+			if err = api.importUser(archived, nil); err == nil {
+				action = "created"
+				ret.Created = append(ret.Created, uid)
+			}
+		case conf.Conflict == ConflictFail:
+			return ret, fmt.Errorf("%s: user already exists", uid)
+		case conf.Conflict == ConflictSkip:
+			action = "skipped"
+			ret.Skipped = append(ret.Skipped, uid)
+		default:
+			if err = api.importUser(archived, current); err == nil {
+				action = "overwritten"
+				ret.Overwritten = append(ret.Overwritten, uid)
+			}
+		}
+		if err != nil {
+			action = "failed"
+			ret.Failed[uid] = err
+		}
+		if conf.Progress != nil {
+			conf.Progress(ArchiveProgress{Done: idx + 1, Total: len(archive.Users), UID: uid, Action: action, Err: err})
+		}
+	}
+	return ret, nil
+}
+
+// subUserAccess translates the permissions returned by the gateway to the access of SubUserConfig
+var subUserAccess = map[string]string{
+	"read":         "read",
+	"write":        "write",
+	"read-write":   "readwrite",
+	"full-control": "full",
+}
+
+// importUser creates archived, or overwrites current with it
+func (api *API) importUser(archived ArchivedUser, current *User) error {
+	user := archived.User
+	uid := user.UserID
+	maxBuckets := user.MaxBuckets
+	suspended := user.Suspended
+	conf := UserConfig{
+		UID:              uid,
+		DisplayName:      user.DisplayName,
+		Email:            user.Email,
+		MaxBuckets:       &maxBuckets,
+		Suspended:        &suspended,
+		OpMask:           user.OpMask,
+		DefaultPlacement: user.DefaultPlacement,
+		PlacementTags:    user.PlacementTags,
+	}
+
+	if current == nil {
+		conf.AccountID = user.AccountID
+		for _, key := range user.Keys {
+			if key.User == uid {
+				conf.AccessKey, conf.SecretKey = key.AccessKey, key.SecretKey
+				break
+			}
+		}
+		created, err := api.CreateUser(conf)
+		if err != nil {
+			return err
+		}
+		current = created
+	} else if _, err := api.UpdateUser(conf); err != nil {
+		return err
+	}
+
+	existing := map[string]bool{}
+	for _, subuser := range current.Subusers {
+		existing[subuser.ID] = true
+	}
+	archivedSubUsers := map[string]bool{}
+	for _, subuser := range user.Subusers {
+		archivedSubUsers[subuser.ID] = true
+	}
+	for _, subuser := range current.Subusers {
+		if !archivedSubUsers[subuser.ID] {
+			if err := api.RemoveSubUser(SubUserConfig{UID: uid, SubUser: subuser.ID, PurgeKeys: true}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, subuser := range user.Subusers {
+		subConf := SubUserConfig{UID: uid, SubUser: subuser.ID, Access: subUserAccess[subuser.Permissions]}
+		var err error
+		if existing[subuser.ID] {
+			_, err = api.UpdateSubUser(subConf)
+		} else {
+			_, err = api.CreateSubUser(subConf)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	wanted := map[string]bool{}
+	for _, key := range user.Keys {
+		wanted[key.AccessKey] = true
+		keyConf := KeyConfig{UID: uid, KeyType: "s3", AccessKey: key.AccessKey, SecretKey: key.SecretKey}
+		if key.User != uid {
+			keyConf.SubUser = key.User
+		}
+		if _, err := api.CreateKey(keyConf); err != nil {
+			return err
+		}
+	}
+	for _, key := range user.SwiftKeys {
+		wanted[key.User] = true
+		if _, err := api.CreateKey(KeyConfig{UID: uid, SubUser: key.User, KeyType: "swift", SecretKey: key.SecretKey}); err != nil {
+			return err
+		}
+	}
+	// in dry run mode nothing was sent, the user is still as read or created
+	refreshed := current
+	var err error
+	if !api.DryRun() {
+		if refreshed, err = api.GetUser(uid); err != nil {
+			return err
+		}
+	}
+	for _, key := range refreshed.Keys {
+		if !wanted[key.AccessKey] {
+			if err = api.RemoveKey(KeyConfig{UID: uid, KeyType: "s3", AccessKey: key.AccessKey}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, key := range refreshed.SwiftKeys {
+		if !wanted[key.User] {
+			if err = api.RemoveKey(KeyConfig{UID: uid, SubUser: key.User, KeyType: "swift", AccessKey: key.User}); err != nil {
+				return err
+			}
+		}
+	}
+
+	if caps := joinCaps(refreshed.Caps); caps != "" && caps != joinCaps(user.Caps) {
+		if _, err = api.DelCapability(CapConfig{UID: uid, UserCaps: caps}); err != nil {
+			return err
+		}
+	}
+	if caps := joinCaps(user.Caps); caps != "" && caps != joinCaps(refreshed.Caps) {
+		if _, err = api.AddCapability(CapConfig{UID: uid, UserCaps: caps}); err != nil {
+			return err
+		}
+	}
+
+	if archived.Quotas == nil {
+		return nil
+	}
+	userQuota, bucketQuota := archived.Quotas.UserQuota, archived.Quotas.BucketQuota
+	for _, quota := range []QuotaConfig{
+		{
+			UID:        uid,
+			QuotaType:  "user",
+			MaxObjects: strconv.Itoa(userQuota.MaxObjects),
+			MaxSizeKB:  strconv.Itoa(userQuota.MaxSizeKb),
+			Enabled:    strconv.FormatBool(userQuota.Enabled),
+		},
+		{
+			UID:        uid,
+			QuotaType:  "bucket",
+			MaxObjects: strconv.Itoa(bucketQuota.MaxObjects),
+			MaxSizeKB:  strconv.Itoa(bucketQuota.MaxSizeKb),
+			Enabled:    strconv.FormatBool(bucketQuota.Enabled),
+		},
+	} {
+		if err = api.UpdateQuota(quota); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinCaps formats caps as expected by CapConfig.UserCaps, sorted by type
+func joinCaps(caps []Capability) string {
+	parts := make([]string, 0, len(caps))
+	for _, capability := range caps {
+		parts = append(parts, fmt.Sprintf("%s=%s", capability.Type, capability.Perm))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ";")
+}
@@ -0,0 +1,211 @@
+package radosAPI
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeUsers is a gateway keeping users in memory
+type fakeUsers struct {
+	lock   sync.Mutex
+	users  map[string]*User
+	quotas map[string]*Quotas
+	calls  []string
+}
+
+func (f *fakeUsers) handler(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	query := r.URL.Query()
+	sub := strings.SplitN(r.URL.RawQuery, "&", 2)[0]
+	if strings.Contains(sub, "=") {
+		sub = ""
+	}
+	uid := query.Get("uid")
+	user := f.users[uid]
+	// the sub resource comes first and shadows the subuser parameter
+	subuser := ""
+	if values := query["subuser"]; len(values) != 0 {
+		subuser = values[len(values)-1]
+	}
+	f.calls = append(f.calls, strings.TrimSpace(r.Method+" "+sub))
+	if r.URL.Path == "/admin/metadata/user" {
+		uids := []string{}
+		for uid := range f.users {
+			uids = append(uids, uid)
+		}
+		json.NewEncoder(w).Encode(uids)
+		return
+	}
+	if user == nil && !(r.Method == "PUT" && sub == "") {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"Code":"NoSuchUser"}`))
+		return
+	}
+	switch r.Method + " " + sub {
+	case "GET ":
+	case "GET quota":
+		quotas := f.quotas[uid]
+		if quotas == nil {
+			quotas = &Quotas{}
+		}
+		json.NewEncoder(w).Encode(quotas)
+		return
+	case "PUT ":
+		user = &User{UserID: uid, DisplayName: query.Get("display-name")}
+		access, secret := query.Get("access-key"), query.Get("secret-key")
+		if access == "" {
+			access, secret = "GENERATED", "generated"
+		}
+		user.Keys = KeysDefinition{{AccessKey: access, SecretKey: secret, User: uid}}
+		f.users[uid] = user
+	case "POST ":
+		user.DisplayName = query.Get("display-name")
+	case "PUT subuser":
+		user.Subusers = append(user.Subusers, SubUsers{{ID: subuser, Permissions: query.Get("access")}}...)
+		json.NewEncoder(w).Encode(user.Subusers)
+		return
+	case "POST subuser":
+		for idx := range user.Subusers {
+			if user.Subusers[idx].ID == subuser {
+				user.Subusers[idx].Permissions = query.Get("access")
+			}
+		}
+		json.NewEncoder(w).Encode(user.Subusers)
+		return
+	case "DELETE subuser":
+		user.Subusers = nil
+	case "PUT key":
+		if query.Get("key-type") == "swift" {
+			user.SwiftKeys = append(user.SwiftKeys, KeysDefinition{{User: subuser, SecretKey: query.Get("secret-key")}}...)
+		} else if query.Get("access-key") != user.Keys[0].AccessKey {
+			user.Keys = append(user.Keys, KeysDefinition{{AccessKey: query.Get("access-key"), SecretKey: query.Get("secret-key"), User: uid}}...)
+		}
+		w.Write([]byte(`[]`))
+		return
+	case "DELETE key":
+		keys := KeysDefinition{}
+		for _, key := range user.Keys {
+			if key.AccessKey != query.Get("access-key") {
+				keys = append(keys, key)
+			}
+		}
+		user.Keys = keys
+	case "PUT caps":
+		user.Caps = nil
+		for _, capability := range strings.Split(query.Get("user-caps"), ";") {
+			tab := strings.SplitN(capability, "=", 2)
+			user.Caps = append(user.Caps, Capability{Type: tab[0], Perm: tab[1]})
+		}
+		w.Write([]byte(`[]`))
+		return
+	case "DELETE caps":
+		user.Caps = nil
+		w.Write([]byte(`[]`))
+		return
+	case "PUT quota":
+		if f.quotas[uid] == nil {
+			f.quotas[uid] = &Quotas{}
+		}
+		if query.Get("quota-type") == "user" {
+			f.quotas[uid].UserQuota.Enabled = query.Get("enabled") == "true"
+			f.quotas[uid].UserQuota.MaxObjects, _ = strconv.Atoi(query.Get("max-objects"))
+		}
+		return
+	}
+	json.NewEncoder(w).Encode(user)
+}
+
+func TestArchive(t *testing.T) {
+	source := &fakeUsers{
+		users: map[string]*User{
+			"john": {
+				UserID:      "john",
+				DisplayName: "John",
+				Keys: KeysDefinition{
+					{AccessKey: "AK1", SecretKey: "SK1", User: "john"},
+					{AccessKey: "AK2", SecretKey: "SK2", User: "john"},
+				},
+				SwiftKeys: KeysDefinition{{User: "john:swift", SecretKey: "SW1"}},
+				Subusers:  SubUsers{{ID: "john:swift", Permissions: "full-control"}},
+				Caps:      []Capability{{Type: "users", Perm: "read"}},
+			},
+		},
+		quotas: map[string]*Quotas{},
+	}
+	source.quotas["john"] = &Quotas{}
+	source.quotas["john"].UserQuota.Enabled = true
+	source.quotas["john"].UserQuota.MaxObjects = 100
+	sourceAPI, sourceServer := newTestAPI(source.handler)
+	defer sourceServer.Close()
+
+	target := &fakeUsers{users: map[string]*User{}, quotas: map[string]*Quotas{}}
+	targetAPI, targetServer := newTestAPI(target.handler)
+	defer targetServer.Close()
+
+	Convey("Testing export and archive formats", t, func() {
+		progress := []ArchiveProgress{}
+		archive, err := sourceAPI.ExportUsers(ExportConfig{Progress: func(p ArchiveProgress) {
+			progress = append(progress, p)
+		}})
+		So(err, ShouldBeNil)
+		So(len(archive.Users), ShouldEqual, 1)
+		So(archive.Users[0].Quotas.UserQuota.MaxObjects, ShouldEqual, 100)
+		So(progress, ShouldResemble, []ArchiveProgress{{Done: 1, Total: 1, UID: "john", Action: "exported"}})
+
+		for _, format := range []string{ArchiveJSON, ArchiveYAML} {
+			buffer := &bytes.Buffer{}
+			err = WriteArchive(buffer, archive, format)
+			So(err, ShouldBeNil)
+			read, err := ReadArchive(buffer)
+			So(err, ShouldBeNil)
+			So(read.Users, ShouldResemble, archive.Users)
+			So(read.CreatedAt.Equal(archive.CreatedAt), ShouldBeTrue)
+		}
+		err = WriteArchive(&bytes.Buffer{}, archive, "xml")
+		So(err, ShouldNotBeNil)
+		_, err = ReadArchive(strings.NewReader(`{"version":42}`))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing import", t, func() {
+		archive, err := sourceAPI.ExportUsers(ExportConfig{UIDs: []string{"john"}})
+		So(err, ShouldBeNil)
+
+		report, err := targetAPI.ImportUsers(archive, ImportConfig{})
+		So(err, ShouldBeNil)
+		So(report.Failed, ShouldBeEmpty)
+		So(report.Created, ShouldResemble, []string{"john"})
+		john := target.users["john"]
+		So(john.Keys, ShouldResemble, source.users["john"].Keys)
+		So(john.SwiftKeys, ShouldResemble, source.users["john"].SwiftKeys)
+		So(john.Subusers[0].Permissions, ShouldEqual, "full")
+		So(john.Caps, ShouldResemble, source.users["john"].Caps)
+		So(target.quotas["john"].UserQuota.Enabled, ShouldBeTrue)
+		So(target.quotas["john"].UserQuota.MaxObjects, ShouldEqual, 100)
+
+		report, err = targetAPI.ImportUsers(archive, ImportConfig{Conflict: ConflictSkip})
+		So(err, ShouldBeNil)
+		So(report.Skipped, ShouldResemble, []string{"john"})
+
+		target.users["john"].DisplayName = "Changed"
+		report, err = targetAPI.ImportUsers(archive, ImportConfig{Conflict: ConflictOverwrite})
+		So(err, ShouldBeNil)
+		So(report.Failed, ShouldBeEmpty)
+		So(report.Overwritten, ShouldResemble, []string{"john"})
+		So(target.users["john"].DisplayName, ShouldEqual, "John")
+
+		_, err = targetAPI.ImportUsers(archive, ImportConfig{Conflict: ConflictFail})
+		So(err, ShouldNotBeNil)
+		_, err = targetAPI.ImportUsers(archive, ImportConfig{Conflict: "merge"})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,336 @@
+package radosAPI
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// CacheConfig cache request
+type CacheConfig struct {
+	TTL        time.Duration // How long a response is served from the cache, 30 seconds by default
+	MaxEntries int           // The maximum number of cached responses, 1000 by default
+}
+
+// CachedAPI serves GetUser and GetBucket from a cache, concurrent identical lookups share one request.
+// The writes sent through CachedAPI invalidate the responses they affect, the writes sent by other
+// clients are seen once the cached responses expire.
+// A lookup canceled by its context returns early, the shared request keeps running for the others.
+// The returned values are shared and must not be modified.
+type CachedAPI struct {
+	*API
+	*cache
+}
+
+// cache is the state shared by a CachedAPI and its copies
+type cache struct {
+	conf     CacheConfig
+	lock     sync.Mutex
+	entries  map[cacheKey]*list.Element
+	lru      *list.List
+	inflight map[cacheKey]*cacheCall
+}
+
+type cacheKey struct {
+	kind   string // user or bucket
+	uid    string
+	bucket string
+	stats  bool
+}
+
+type cacheEntry struct {
+	key     cacheKey
+	value   interface{}
+	expires time.Time
+}
+
+type cacheCall struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+	panic interface{}
+}
+
+// errLookupPanic is returned to the concurrent lookups of a request which panicked
+var errLookupPanic = errors.New("the shared request panicked")
+
+// NewCachedAPI returns api wrapped in a cache
+func NewCachedAPI(api *API, conf CacheConfig) *CachedAPI {
+	if conf.TTL <= 0 {
+		conf.TTL = 30 * time.Second
+	}
+	if conf.MaxEntries <= 0 {
+		conf.MaxEntries = 1000
+	}
+	return &CachedAPI{
+		API: api,
+		cache: &cache{
+			conf:     conf,
+			entries:  map[cacheKey]*list.Element{},
+			lru:      list.New(),
+			inflight: map[cacheKey]*cacheCall{},
+		},
+	}
+}
+
+// WithContext returns a copy of c sending its requests with ctx, the cache is shared with c
+func (c *CachedAPI) WithContext(ctx context.Context) *CachedAPI {
+	return &CachedAPI{API: c.API.WithContext(ctx), cache: c.cache}
+}
+
+// lookup returns the cached value of key, or calls fetch once for all the concurrent lookups of key.
+// fetch runs with a copy of the API which is not canceled with the lookups.
+func (c *CachedAPI) lookup(key cacheKey, fetch func(api *API) (interface{}, error)) (interface{}, error) {
+	ctx := c.API.context()
+
+	c.lock.Lock()
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(elem)
+			c.lock.Unlock()
+			return entry.value, nil
+		}
+		c.remove(elem)
+	}
+	call, shared := c.inflight[key]
+	if !shared {
+		if err := ctx.Err(); err != nil {
+			c.lock.Unlock()
+			return nil, err
+		}
+		call = &cacheCall{done: make(chan struct{}), err: errLookupPanic}
+		c.inflight[key] = call
+		go c.run(key, call, c.API.WithContext(context.WithoutCancel(ctx)), fetch)
+	}
+	c.lock.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	// the panic is raised again in the lookup which started the call
+	if call.panic != nil && !shared {
+		panic(call.panic)
+	}
+	return call.value, call.err
+}
+
+// run calls fetch and stores its value, unless key was invalidated in the meantime
+func (c *CachedAPI) run(key cacheKey, call *cacheCall, api *API, fetch func(api *API) (interface{}, error)) {
+	defer func() {
+		call.panic = recover()
+		c.lock.Lock()
+		if c.inflight[key] == call {
+			delete(c.inflight, key)
+			if call.err == nil {
+				c.store(key, call.value)
+			}
+		}
+		c.lock.Unlock()
+		close(call.done)
+	}()
+	call.value, call.err = fetch(api)
+}
+
+func (c *CachedAPI) store(key cacheKey, value interface{}) {
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{
+		key:     key,
+		value:   value,
+		expires: time.Now().Add(c.conf.TTL),
+	})
+	for c.lru.Len() > c.conf.MaxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *CachedAPI) remove(elem *list.Element) {
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+	c.lru.Remove(elem)
+}
+
+// invalidate drops the cached and in flight responses matching match, the in flight ones are not stored
+func (c *CachedAPI) invalidate(match func(key cacheKey) bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for key, elem := range c.entries {
+		if match(key) {
+			c.remove(elem)
+		}
+	}
+	for key := range c.inflight {
+		if match(key) {
+			delete(c.inflight, key)
+		}
+	}
+}
+
+// invalidateUser drops the responses describing uid and the lists of its buckets
+func (c *CachedAPI) invalidateUser(uid, tenant string) {
+	if tenant != "" {
+		uid = tenant + "$" + uid
+	}
+	c.invalidate(func(key cacheKey) bool {
+		if key.kind == "user" {
+			return key.uid == uid || key.uid == ""
+		}
+		return key.uid == uid
+	})
+}
+
+// invalidateBucket drops the responses describing bucket and the lists of buckets
+func (c *CachedAPI) invalidateBucket(bucket string) {
+	c.invalidate(func(key cacheKey) bool {
+		return key.kind == "bucket" && (key.bucket == bucket || key.bucket == "")
+	})
+}
+
+// Flush drops every cached response
+func (c *CachedAPI) Flush() {
+	c.invalidate(func(key cacheKey) bool {
+		return true
+	})
+}
+
+// GetUser gets user information, from the cache if possible
+func (c *CachedAPI) GetUser(uid ...string) (*User, error) {
+	key := cacheKey{kind: "user"}
+	if len(uid) != 0 {
+		key.uid = uid[0]
+	}
+	value, err := c.lookup(key, func(api *API) (interface{}, error) {
+		return api.GetUser(uid...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(*User), nil
+}
+
+// GetBucket gets information about a subset of the existing buckets, from the cache if possible
+func (c *CachedAPI) GetBucket(conf BucketConfig) (Buckets, error) {
+	key := cacheKey{kind: "bucket", uid: conf.UID, bucket: conf.Bucket, stats: conf.Stats}
+	value, err := c.lookup(key, func(api *API) (interface{}, error) {
+		return api.GetBucket(BucketConfig{UID: conf.UID, Bucket: conf.Bucket, Stats: conf.Stats})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return value.(Buckets), nil
+}
+
+// CreateUser creates a new user and invalidates the list of users
+func (c *CachedAPI) CreateUser(conf UserConfig) (*User, error) {
+	defer c.invalidateUser(conf.UID, conf.Tenant)
+	return c.API.CreateUser(conf)
+}
+
+// UpdateUser modifies a user and invalidates its cached information
+func (c *CachedAPI) UpdateUser(conf UserConfig) (*User, error) {
+	defer c.invalidateUser(conf.UID, conf.Tenant)
+	return c.API.UpdateUser(conf)
+}
+
+// RemoveUser removes an existing user and invalidates its cached information,
+// and the cached buckets if its data is purged
+func (c *CachedAPI) RemoveUser(conf UserConfig) error {
+	defer c.invalidateUser(conf.UID, conf.Tenant)
+	if conf.PurgeData {
+		defer c.invalidate(func(key cacheKey) bool {
+			return key.kind == "bucket"
+		})
+	}
+	return c.API.RemoveUser(conf)
+}
+
+// CreateSubUser creates a new subuser and invalidates the cached information of its user
+func (c *CachedAPI) CreateSubUser(conf SubUserConfig) (*SubUsers, error) {
+	defer c.invalidateUser(conf.UID, "")
+	return c.API.CreateSubUser(conf)
+}
+
+// UpdateSubUser modifies an existing subuser and invalidates the cached information of its user
+func (c *CachedAPI) UpdateSubUser(conf SubUserConfig) (*SubUsers, error) {
+	defer c.invalidateUser(conf.UID, "")
+	return c.API.UpdateSubUser(conf)
+}
+
+// RemoveSubUser removes an existing subuser and invalidates the cached information of its user
+func (c *CachedAPI) RemoveSubUser(conf SubUserConfig) error {
+	defer c.invalidateUser(conf.UID, "")
+	return c.API.RemoveSubUser(conf)
+}
+
+// CreateKey creates a new key and invalidates the cached information of its user
+func (c *CachedAPI) CreateKey(conf KeyConfig) (*KeysDefinition, error) {
+	defer c.invalidateUser(conf.UID, "")
+	return c.API.CreateKey(conf)
+}
+
+// RemoveKey removes an existing key and invalidates the cached information of its user
+func (c *CachedAPI) RemoveKey(conf KeyConfig) error {
+	defer c.invalidateUser(conf.UID, "")
+	return c.API.RemoveKey(conf)
+}
+
+// AddCapability adds capabilities and invalidates the cached information of the user
+func (c *CachedAPI) AddCapability(conf CapConfig) ([]Capability, error) {
+	defer c.invalidateUser(conf.UID, "")
+	return c.API.AddCapability(conf)
+}
+
+// DelCapability removes capabilities and invalidates the cached information of the user
+func (c *CachedAPI) DelCapability(conf CapConfig) ([]Capability, error) {
+	defer c.invalidateUser(conf.UID, "")
+	return c.API.DelCapability(conf)
+}
+
+// UpdateQuota updates user's quotas and invalidates the cached information of the user and of its buckets
+func (c *CachedAPI) UpdateQuota(conf QuotaConfig) error {
+	defer c.invalidateUser(conf.UID, "")
+	defer c.invalidateBucket(conf.Bucket)
+	return c.API.UpdateQuota(conf)
+}
+
+// UpdateBuckQuota updates bucket's quotas and invalidates the cached information of the bucket
+func (c *CachedAPI) UpdateBuckQuota(conf QuotaConfig) error {
+	defer c.invalidateUser(conf.UID, "")
+	defer c.invalidateBucket(conf.Bucket)
+	return c.API.UpdateBuckQuota(conf)
+}
+
+// RemoveBucket removes an existing bucket and invalidates its cached information
+func (c *CachedAPI) RemoveBucket(conf BucketConfig) error {
+	defer c.invalidateBucket(conf.Bucket)
+	return c.API.RemoveBucket(conf)
+}
+
+// LinkBucket links a bucket to a specified user and invalidates its cached information
+func (c *CachedAPI) LinkBucket(conf BucketConfig) error {
+	defer c.invalidateBucket(conf.Bucket)
+	return c.API.LinkBucket(conf)
+}
+
+// UnlinkBucket unlinks a bucket from a specified user and invalidates its cached information
+func (c *CachedAPI) UnlinkBucket(conf BucketConfig) error {
+	defer c.invalidateBucket(conf.Bucket)
+	return c.API.UnlinkBucket(conf)
+}
+
+// RemoveObject removes an existing object and invalidates the cached information of its bucket
+func (c *CachedAPI) RemoveObject(conf BucketConfig) error {
+	defer c.invalidateBucket(conf.Bucket)
+	return c.API.RemoveObject(conf)
+}
+
+// CheckBucket checks the index of an existing bucket and invalidates its cached information
+func (c *CachedAPI) CheckBucket(conf BucketConfig) (string, error) {
+	defer c.invalidateBucket(conf.Bucket)
+	return c.API.CheckBucket(conf)
+}
@@ -0,0 +1,230 @@
+package radosAPI
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCachedAPI(t *testing.T) {
+	var hits int32
+
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Method == "GET" {
+			atomic.AddInt32(&hits, 1)
+		}
+		time.Sleep(5 * time.Millisecond)
+		switch {
+		case query.Get("uid") == "missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"Code":"NoSuchUser"}`)
+		case r.Method == "PUT":
+			fmt.Fprint(w, `[]`)
+		case r.URL.Path == "/admin/bucket":
+			fmt.Fprint(w, `["photos"]`)
+		default:
+			fmt.Fprintf(w, `{"user_id":%q}`, query.Get("uid"))
+		}
+	})
+	defer server.Close()
+
+	Convey("Testing lookups are cached", t, func() {
+		atomic.StoreInt32(&hits, 0)
+		cached := NewCachedAPI(api, CacheConfig{})
+		for i := 0; i < 3; i++ {
+			user, err := cached.GetUser("john")
+			So(err, ShouldBeNil)
+			So(user.UserID, ShouldEqual, "john")
+			buckets, err := cached.GetBucket(BucketConfig{UID: "john"})
+			So(err, ShouldBeNil)
+			So(buckets[0].Name, ShouldEqual, "photos")
+		}
+		So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+	})
+
+	Convey("Testing concurrent lookups share one request", t, func() {
+		atomic.StoreInt32(&hits, 0)
+		cached := NewCachedAPI(api, CacheConfig{})
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				cached.GetUser("john")
+			}()
+		}
+		wg.Wait()
+		So(atomic.LoadInt32(&hits), ShouldEqual, 1)
+	})
+
+	Convey("Testing writes invalidate the cache", t, func() {
+		atomic.StoreInt32(&hits, 0)
+		cached := NewCachedAPI(api, CacheConfig{})
+		cached.GetUser("john")
+		cached.GetUser("jane")
+		cached.GetBucket(BucketConfig{UID: "john"})
+		_, err := cached.CreateKey(KeyConfig{UID: "john"})
+		So(err, ShouldBeNil)
+		cached.GetUser("john")
+		cached.GetUser("jane")
+		cached.GetBucket(BucketConfig{UID: "john"})
+		So(atomic.LoadInt32(&hits), ShouldEqual, 5)
+
+		cached.GetBucket(BucketConfig{Bucket: "photos", Stats: true})
+		err = cached.RemoveBucket(BucketConfig{Bucket: "photos"})
+		So(err, ShouldBeNil)
+		cached.GetBucket(BucketConfig{Bucket: "photos", Stats: true})
+		So(atomic.LoadInt32(&hits), ShouldEqual, 7)
+	})
+
+	Convey("Testing TTL and size bounds", t, func() {
+		atomic.StoreInt32(&hits, 0)
+		cached := NewCachedAPI(api, CacheConfig{TTL: 20 * time.Millisecond, MaxEntries: 1})
+		cached.GetUser("john")
+		cached.GetUser("jane")
+		cached.GetUser("john")
+		So(atomic.LoadInt32(&hits), ShouldEqual, 3)
+		time.Sleep(30 * time.Millisecond)
+		cached.GetUser("john")
+		So(atomic.LoadInt32(&hits), ShouldEqual, 4)
+	})
+
+	Convey("Testing errors are not cached", t, func() {
+		atomic.StoreInt32(&hits, 0)
+		cached := NewCachedAPI(api, CacheConfig{})
+		_, err := cached.GetUser("missing")
+		So(err, ShouldNotBeNil)
+		_, err = cached.GetUser("missing")
+		So(err, ShouldNotBeNil)
+		So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+	})
+
+	Convey("Testing a panicking request releases the concurrent lookups", t, func() {
+		var (
+			cached  = NewCachedAPI(api, CacheConfig{})
+			key     = cacheKey{kind: "user", uid: "john"}
+			started = make(chan struct{})
+			release = make(chan struct{})
+			waited  = make(chan error)
+		)
+
+		go func() {
+			defer func() { recover() }()
+			cached.lookup(key, func(*API) (interface{}, error) {
+				close(started)
+				<-release
+				panic("boom")
+			})
+		}()
+		<-started
+		go func() {
+			_, err := cached.lookup(key, func(*API) (interface{}, error) {
+				return "john", nil
+			})
+			waited <- err
+		}()
+		time.Sleep(10 * time.Millisecond)
+		close(release)
+		So(<-waited, ShouldEqual, errLookupPanic)
+
+		value, err := cached.lookup(key, func(*API) (interface{}, error) {
+			return "john", nil
+		})
+		So(err, ShouldBeNil)
+		So(value, ShouldEqual, "john")
+	})
+
+	Convey("Testing a canceled lookup does not cancel the concurrent lookups", t, func() {
+		var (
+			cached      = NewCachedAPI(api, CacheConfig{})
+			key         = cacheKey{kind: "user", uid: "john"}
+			ctx, cancel = context.WithCancel(context.Background())
+			started     = make(chan struct{})
+			release     = make(chan struct{})
+			canceled    = make(chan error)
+			waited      = make(chan interface{})
+		)
+
+		go func() {
+			_, err := cached.WithContext(ctx).lookup(key, func(api *API) (interface{}, error) {
+				close(started)
+				<-release
+				return "john", api.context().Err()
+			})
+			canceled <- err
+		}()
+		<-started
+		go func() {
+			value, _ := cached.lookup(key, func(*API) (interface{}, error) {
+				return "jane", nil
+			})
+			waited <- value
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+		So(<-canceled, ShouldEqual, context.Canceled)
+		close(release)
+		So(<-waited, ShouldEqual, "john")
+		So(cached.entries, ShouldContainKey, key)
+	})
+
+	Convey("Testing writes only discard the lookups they affect", t, func() {
+		var (
+			cached  = NewCachedAPI(api, CacheConfig{})
+			john    = cacheKey{kind: "user", uid: "john"}
+			bucket  = cacheKey{kind: "bucket", uid: "john", bucket: "photos"}
+			started = make(chan struct{}, 2)
+			release = make(chan struct{})
+			wg      = sync.WaitGroup{}
+		)
+
+		for _, key := range []cacheKey{john, bucket} {
+			wg.Add(1)
+			go func(key cacheKey) {
+				defer wg.Done()
+				cached.lookup(key, func(*API) (interface{}, error) {
+					started <- struct{}{}
+					<-release
+					return "stale", nil
+				})
+			}(key)
+		}
+		<-started
+		<-started
+		cached.invalidateUser("jane", "")
+		cached.invalidateBucket("photos")
+		close(release)
+		wg.Wait()
+		So(cached.entries, ShouldContainKey, john)
+		So(cached.entries, ShouldNotContainKey, bucket)
+	})
+
+	Convey("Testing copies with a context share the cache", t, func() {
+		atomic.StoreInt32(&hits, 0)
+		cached := NewCachedAPI(api, CacheConfig{})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		canceled := cached.WithContext(ctx)
+
+		_, err := cached.GetUser("john")
+		So(err, ShouldBeNil)
+		user, err := canceled.GetUser("john")
+		So(err, ShouldBeNil)
+		So(user.UserID, ShouldEqual, "john")
+		_, err = canceled.GetUser("jane")
+		So(err, ShouldNotBeNil)
+		So(atomic.LoadInt32(&hits), ShouldEqual, 1)
+
+		canceled.Flush()
+		_, err = cached.GetUser("john")
+		So(err, ShouldBeNil)
+		So(atomic.LoadInt32(&hits), ShouldEqual, 2)
+	})
+}
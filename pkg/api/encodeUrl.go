@@ -3,6 +3,8 @@ package radosAPI
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/QuentinPerez/go-encodeUrl"
@@ -11,6 +13,8 @@ import (
 func init() {
 	encurl.AddEncodeFunc(ifTimeIsNotNilCeph)
 	encurl.AddEncodeFunc(boolIfNotNil)
+	encurl.AddEncodeFunc(int64IfNotNil)
+	encurl.AddEncodeFunc(joinIfNotEmpty)
 }
 
 func ifTimeIsNotNilCeph(obj interface{}) (string, bool, error) {
@@ -37,3 +41,23 @@ func boolIfNotNil(obj interface{}) (string, bool, error) {
 	}
 	return "", false, errors.New("this field should be a *boolean")
 }
+
+func int64IfNotNil(obj interface{}) (string, bool, error) {
+	if val, ok := obj.(*int64); ok {
+		if val != nil {
+			return strconv.FormatInt(*val, 10), true, nil
+		}
+		return "", false, nil
+	}
+	return "", false, errors.New("this field should be a *int64")
+}
+
+func joinIfNotEmpty(obj interface{}) (string, bool, error) {
+	if val, ok := obj.([]string); ok {
+		if len(val) > 0 {
+			return strings.Join(val, ","), true, nil
+		}
+		return "", false, nil
+	}
+	return "", false, errors.New("this field should be a []string")
+}
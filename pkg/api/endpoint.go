@@ -0,0 +1,195 @@
+package radosAPI
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Balancer selects the gateway endpoint of each request
+type Balancer int
+
+const (
+	RoundRobin   Balancer = iota // Cycle through the healthy endpoints
+	LeastLatency                 // Use the healthy endpoint with the lowest average latency
+)
+
+// defaultEjection is how long an endpoint is skipped after a connection error
+const defaultEjection = 30 * time.Second
+
+// EndpointStatus represents the health of a gateway endpoint
+type EndpointStatus struct {
+	Host         string
+	Healthy      bool
+	Failures     int           // The consecutive connection errors
+	Latency      time.Duration // The average latency of the requests
+	EjectedUntil time.Time     // The endpoint is skipped until then, unless no other endpoint is healthy
+}
+
+type endpoint struct {
+	host         string
+	failures     int
+	latency      time.Duration
+	ejectedUntil time.Time
+}
+
+// endpointPool tracks the health of the endpoints, it is shared with the copies returned by WithContext
+type endpointPool struct {
+	lock      sync.Mutex
+	endpoints []*endpoint
+	next      int
+	balancer  Balancer
+	ejection  time.Duration
+}
+
+// newEndpointPool parses a comma separated list of endpoints
+func newEndpointPool(hosts string) (*endpointPool, error) {
+	pool := &endpointPool{ejection: defaultEjection}
+
+	for _, host := range strings.Split(hosts, ",") {
+		host = strings.TrimRight(strings.TrimSpace(host), "/")
+		if host == "" {
+			continue
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{host: host})
+	}
+	if len(pool.endpoints) == 0 {
+		return nil, errors.New("at least one endpoint is required")
+	}
+	return pool, nil
+}
+
+// pick returns the endpoint of the next request among the ones not in tried.
+// If every endpoint is ejected, the one coming back first is returned.
+func (pool *endpointPool) pick(tried map[*endpoint]bool) *endpoint {
+	var (
+		now      = time.Now()
+		ret      *endpoint
+		fallback *endpoint
+	)
+
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	for idx := range pool.endpoints {
+		candidate := (pool.next + idx) % len(pool.endpoints)
+		ep := pool.endpoints[candidate]
+		if tried[ep] {
+			continue
+		}
+		if now.Before(ep.ejectedUntil) {
+			if fallback == nil || ep.ejectedUntil.Before(fallback.ejectedUntil) {
+				fallback = ep
+			}
+			continue
+		}
+		if pool.balancer == RoundRobin {
+			pool.next = candidate + 1
+			return ep
+		}
+		if ret == nil || ep.latency < ret.latency {
+			ret = ep
+		}
+	}
+	if ret == nil {
+		return fallback
+	}
+	return ret
+}
+
+// report records the outcome of a request sent to ep
+func (pool *endpointPool) report(ep *endpoint, latency time.Duration, failed bool) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+	if failed {
+		ep.failures++
+		ep.ejectedUntil = time.Now().Add(pool.ejection)
+		return
+	}
+	ep.failures = 0
+	ep.ejectedUntil = time.Time{}
+	if ep.latency == 0 {
+		ep.latency = latency
+	} else {
+		ep.latency += (latency - ep.latency) / 5
+	}
+}
+
+func (pool *endpointPool) size() int {
+	return len(pool.endpoints)
+}
+
+// isConnectionError returns true if err means the endpoint couldn't be reached
+func isConnectionError(err error) bool {
+	var urlErr *url.Error
+
+	return errors.As(err, &urlErr)
+}
+
+// isFailover returns true if a failed request may be sent to another endpoint.
+// Only GET and HEAD requests are sent again.
+func isFailover(verb string, statusCode int, connErr bool) bool {
+	if verb != "GET" && verb != "HEAD" {
+		return false
+	}
+	switch {
+	case connErr:
+		return true
+	case statusCode == http.StatusBadGateway, statusCode == http.StatusServiceUnavailable, statusCode == http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// send sends a request to the endpoints of api, following route, until one of them answers
+func (api *API) send(ctx context.Context, req request, route string) (body []byte, statusCode int, host string, err error) {
+	tried := map[*endpoint]bool{}
+
+	for {
+		ep := api.endpoints.pick(tried)
+		tried[ep] = true
+		start := time.Now()
+		body, statusCode, err = api.makeRequest(ctx, req, ep.host+route)
+		connErr := statusCode == 0 && isConnectionError(err) && ctx.Err() == nil
+		api.endpoints.report(ep, time.Since(start), connErr)
+		if !isFailover(req.verb, statusCode, connErr) || len(tried) == api.endpoints.size() {
+			return body, statusCode, ep.host, err
+		}
+	}
+}
+
+// SetBalancer sets how the endpoint of each request is selected, RoundRobin by default
+func (api *API) SetBalancer(balancer Balancer) {
+	api.endpoints.lock.Lock()
+	api.endpoints.balancer = balancer
+	api.endpoints.lock.Unlock()
+}
+
+// SetEjection sets how long an endpoint is skipped after a connection error, 30 seconds by default
+func (api *API) SetEjection(ejection time.Duration) {
+	api.endpoints.lock.Lock()
+	api.endpoints.ejection = ejection
+	api.endpoints.lock.Unlock()
+}
+
+// Endpoints returns the health of the gateway endpoints
+func (api *API) Endpoints() []EndpointStatus {
+	now := time.Now()
+	ret := []EndpointStatus{}
+
+	api.endpoints.lock.Lock()
+	defer api.endpoints.lock.Unlock()
+	for _, ep := range api.endpoints.endpoints {
+		ret = append(ret, EndpointStatus{
+			Host:         ep.host,
+			Healthy:      !now.Before(ep.ejectedUntil),
+			Failures:     ep.failures,
+			Latency:      ep.latency,
+			EjectedUntil: ep.ejectedUntil,
+		})
+	}
+	return ret
+}
@@ -0,0 +1,97 @@
+package radosAPI
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestEndpoints(t *testing.T) {
+	hits := map[string]int{}
+	newServer := func(name string, delay time.Duration) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(delay)
+			hits[name]++
+			fmt.Fprint(w, `{"user_id":"john"}`)
+		}))
+	}
+	fast := newServer("fast", 0)
+	defer fast.Close()
+	slow := newServer("slow", 20*time.Millisecond)
+	defer slow.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	Convey("Testing New with a list of endpoints", t, func() {
+		api, err := New(" , ", "access", "secret")
+		So(api, ShouldBeNil)
+		So(err.Error(), ShouldEqual, "at least one endpoint is required")
+		api, err = New(fast.URL+", "+slow.URL+"/", "access", "secret")
+		So(err, ShouldBeNil)
+		endpoints := api.Endpoints()
+		So(len(endpoints), ShouldEqual, 2)
+		So(endpoints[1].Host, ShouldEqual, slow.URL)
+	})
+
+	Convey("Testing round robin", t, func() {
+		hits = map[string]int{}
+		api, _ := New(fast.URL+","+slow.URL, "access", "secret")
+		for i := 0; i < 4; i++ {
+			_, err := api.GetUser("john")
+			So(err, ShouldBeNil)
+		}
+		So(hits["fast"], ShouldEqual, 2)
+		So(hits["slow"], ShouldEqual, 2)
+	})
+
+	Convey("Testing least latency", t, func() {
+		hits = map[string]int{}
+		api, _ := New(slow.URL+","+fast.URL, "access", "secret")
+		api.SetBalancer(LeastLatency)
+		for i := 0; i < 6; i++ {
+			_, err := api.GetUser("john")
+			So(err, ShouldBeNil)
+		}
+		So(hits["slow"], ShouldEqual, 1)
+		So(hits["fast"], ShouldEqual, 5)
+	})
+
+	Convey("Testing failover of safe requests", t, func() {
+		hits = map[string]int{}
+		api, _ := New(down.URL+","+fast.URL, "access", "secret")
+		_, err := api.GetUser("john")
+		So(err, ShouldBeNil)
+		So(hits["fast"], ShouldEqual, 1)
+		endpoints := api.Endpoints()
+		So(endpoints[0].Healthy, ShouldBeFalse)
+		So(endpoints[0].Failures, ShouldEqual, 1)
+		So(endpoints[1].Healthy, ShouldBeTrue)
+
+		_, err = api.GetUser("john")
+		So(err, ShouldBeNil)
+		So(hits["fast"], ShouldEqual, 2)
+	})
+
+	Convey("Testing unsafe requests are not sent again", t, func() {
+		hits = map[string]int{}
+		api, _ := New(down.URL+","+fast.URL, "access", "secret")
+		_, err := api.CreateUser(UserConfig{UID: "john", DisplayName: "John"})
+		So(err, ShouldNotBeNil)
+		So(hits["fast"], ShouldEqual, 0)
+		So(api.Endpoints()[0].Healthy, ShouldBeFalse)
+	})
+
+	Convey("Testing every endpoint ejected", t, func() {
+		api, _ := New(down.URL, "access", "secret")
+		api.SetEjection(time.Hour)
+		_, err := api.GetUser("john")
+		So(err, ShouldNotBeNil)
+		_, err = api.GetUser("john")
+		So(err, ShouldNotBeNil)
+		So(api.Endpoints()[0].Failures, ShouldEqual, 2)
+	})
+}
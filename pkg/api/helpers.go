@@ -3,6 +3,7 @@ package radosAPI
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/url"
 	"time"
 
@@ -43,7 +44,7 @@ func (api *API) GetUsage(conf UsageConfig) (*Usage, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("GET", "/usage", values, true)
+	body, _, err := api.call("GetUsage", "GET", "/usage", values, true)
 	if err != nil {
 		return nil, err
 	}
@@ -72,8 +73,12 @@ func (api *API) DeleteUsage(conf UsageConfig) error {
 	if len(errs) > 0 {
 		return errs[0]
 	}
+	guarded, err := api.guardUsage(conf)
+	if err != nil {
+		return err
+	}
 	values.Add("format", "json")
-	_, _, err := api.call("DELETE", "/usage", values, true)
+	_, _, err = guarded.call("DeleteUsage", "DELETE", "/usage", values, true)
 	return err
 }
 
@@ -93,7 +98,7 @@ func (api *API) GetUser(uid ...string) (*User, error) {
 	if len(uid) != 0 {
 		values.Add("uid", uid[0])
 	}
-	body, _, err := api.call("GET", "/user", values, true)
+	body, _, err := api.call("GetUser", "GET", "/user", values, true)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +108,59 @@ func (api *API) GetUser(uid ...string) (*User, error) {
 	return ret, nil
 }
 
+// UserStatsConfig user stats request
+type UserStatsConfig struct {
+	UID       string `url:"uid,ifStringIsNotEmpty"`  // The user to retrieve statistics for
+	SyncStats bool   `url:"sync-stats,ifBoolIsTrue"` // Update the user statistics from its buckets before returning them
+}
+
+// GetUserStats gets the storage statistics of a user, summed over all its buckets.
+// Without SyncStats the statistics may lag behind the bucket statistics.
+//
+// !! caps: users=read !!
+//
+// @UID
+// @SyncStats
+//
+func (api *API) GetUserStats(conf UserStatsConfig) (*UserStats, error) {
+	if conf.UID == "" {
+		return nil, errors.New("UID field is required")
+	}
+	var (
+		values = url.Values{}
+		errs   []error
+		ret    struct {
+			Stats *UserStats `json:"stats"`
+		}
+	)
+
+	values, errs = encurl.Translate(conf)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	values.Add("stats", "true")
+	values.Add("format", "json")
+	body, _, err := api.call("GetUserStats", "GET", "/user", values, true)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(body, &ret); err != nil {
+		return nil, err
+	}
+	if ret.Stats == nil {
+		return nil, errors.New("stats are missing from the response")
+	}
+	return ret.Stats, nil
+}
+
+// SyncUserStats updates the storage statistics of a user from its buckets and returns them
+//
+// !! caps: users=read !!
+//
+func (api *API) SyncUserStats(uid string) (*UserStats, error) {
+	return api.GetUserStats(UserStatsConfig{UID: uid, SyncStats: true})
+}
+
 // GetUIDs gets all UIDs.
 //
 // !! caps: users=read !!
@@ -112,7 +170,7 @@ func (api *API) GetUIDs() ([]string, error) {
 	values := url.Values{}
 
 	values.Add("format", "json")
-	body, _, err := api.call("GET", "/metadata/user", values, true)
+	body, _, err := api.call("GetUIDs", "GET", "/metadata/user", values, true)
 	if err != nil {
 		return ret, err
 	}
@@ -145,17 +203,24 @@ func (api *API) GetUsers() ([]*User, error) {
 
 // UserConfig user request
 type UserConfig struct {
-	UID         string `url:"uid,ifStringIsNotEmpty"`          // The user ID to be created
-	DisplayName string `url:"display-name,ifStringIsNotEmpty"` // The display name of the user to be created
-	Email       string `url:"email,ifStringIsNotEmpty"`        // The email address associated with the user
-	KeyType     string `url:"key-type,ifStringIsNotEmpty"`     // Key type to be generated, options are: swift, s3 (default)
-	AccessKey   string `url:"access-key,ifStringIsNotEmpty"`   // Specify access key
-	SecretKey   string `url:"secret-key,ifStringIsNotEmpty"`   // Specify secret key
-	UserCaps    string `url:"user-caps,ifStringIsNotEmpty"`    // User capabilities
-	MaxBuckets  *int   `url:"max-buckets,itoaIfNotNil"`        // Specify the maximum number of buckets the user can own
-	GenerateKey bool   `url:"generate-key,ifBoolIsTrue"`       // Generate a new key pair and add to the existing keyring
-	Suspended   *bool  `url:"suspended,boolIfNotNil"`          // Specify whether the user should be suspended
-	PurgeData   bool   `url:"purge-data,ifBoolIsTrue"`         // When specified the buckets and objects belonging to the user will also be removed
+	UID              string   `url:"uid,ifStringIsNotEmpty"`               // The user ID to be created
+	Tenant           string   `url:"tenant,ifStringIsNotEmpty"`            // The tenant the user belongs to
+	DisplayName      string   `url:"display-name,ifStringIsNotEmpty"`      // The display name of the user to be created
+	Email            string   `url:"email,ifStringIsNotEmpty"`             // The email address associated with the user
+	KeyType          string   `url:"key-type,ifStringIsNotEmpty"`          // Key type to be generated, options are: swift, s3 (default)
+	AccessKey        string   `url:"access-key,ifStringIsNotEmpty"`        // Specify access key
+	SecretKey        string   `url:"secret-key,ifStringIsNotEmpty"`        // Specify secret key
+	UserCaps         string   `url:"user-caps,ifStringIsNotEmpty"`         // User capabilities
+	MaxBuckets       *int     `url:"max-buckets,itoaIfNotNil"`             // Specify the maximum number of buckets the user can own. 0 removes the limit, a negative value disables bucket creation
+	GenerateKey      bool     `url:"generate-key,ifBoolIsTrue"`            // Generate a new key pair and add to the existing keyring
+	Suspended        *bool    `url:"suspended,boolIfNotNil"`               // Specify whether the user should be suspended
+	PurgeData        bool     `url:"purge-data,ifBoolIsTrue"`              // When specified the buckets and objects belonging to the user will also be removed
+	OpMask           string   `url:"op-mask,ifStringIsNotEmpty"`           // The operations the user is allowed to perform, a comma separated list of read, write, delete or *
+	DefaultPlacement string   `url:"default-placement,ifStringIsNotEmpty"` // The placement target of the buckets created by the user, optionally followed by /storage-class
+	PlacementTags    []string `url:"placement-tags,joinIfNotEmpty"`        // The placement tags the user is allowed to use
+	Exclusive        bool     `url:"exclusive,ifBoolIsTrue"`               // Fail instead of modifying the user if it already exists
+	AccountID        string   `url:"account-id,ifStringIsNotEmpty"`        // The account the user is created in
+	AccountRoot      *bool    `url:"account-root,boolIfNotNil"`            // Specify whether the user is the root user of its account
 }
 
 // CreateUser creates a new user. By Default, a S3 key pair will be created automatically and returned in the response.
@@ -166,6 +231,7 @@ type UserConfig struct {
 // !! caps: users=write !!
 //
 // @UID
+// @Tenant
 // @DisplayName
 // @Email
 // @KeyType
@@ -175,6 +241,12 @@ type UserConfig struct {
 // @GenerateKey
 // @MaxBuckets
 // @Suspended
+// @OpMask
+// @DefaultPlacement
+// @PlacementTags
+// @AccountID
+// @AccountRoot
+// @Exclusive
 //
 func (api *API) CreateUser(conf UserConfig) (*User, error) {
 	if conf.UID == "" {
@@ -183,6 +255,11 @@ func (api *API) CreateUser(conf UserConfig) (*User, error) {
 	if conf.DisplayName == "" {
 		return nil, errors.New("DisplayName field is required")
 	}
+	if conf.AccountID != "" {
+		if err := api.requireFeature(FeatureAccounts); err != nil {
+			return nil, err
+		}
+	}
 
 	var (
 		ret    = &User{}
@@ -195,7 +272,7 @@ func (api *API) CreateUser(conf UserConfig) (*User, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("PUT", "/user", values, true)
+	body, _, err := api.call("CreateUser", "PUT", "/user", values, true)
 	if err != nil {
 		return nil, err
 	}
@@ -210,6 +287,7 @@ func (api *API) CreateUser(conf UserConfig) (*User, error) {
 // !! caps: users=write !!
 //
 // @UID
+// @Tenant
 // @DisplayName
 // @Email
 // @KeyType
@@ -219,11 +297,21 @@ func (api *API) CreateUser(conf UserConfig) (*User, error) {
 // @GenerateKey
 // @MaxBuckets
 // @Suspended
+// @OpMask
+// @DefaultPlacement
+// @PlacementTags
+// @AccountID
+// @AccountRoot
 //
 func (api *API) UpdateUser(conf UserConfig) (*User, error) {
 	if conf.UID == "" {
 		return nil, errors.New("UID field is required")
 	}
+	if conf.AccountID != "" {
+		if err := api.requireFeature(FeatureAccounts); err != nil {
+			return nil, err
+		}
+	}
 
 	var (
 		ret    = &User{}
@@ -236,7 +324,7 @@ func (api *API) UpdateUser(conf UserConfig) (*User, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("POST", "/user", values, true)
+	body, _, err := api.call("UpdateUser", "POST", "/user", values, true)
 	if err != nil {
 		return nil, err
 	}
@@ -251,6 +339,7 @@ func (api *API) UpdateUser(conf UserConfig) (*User, error) {
 // !! caps: users=write !!
 //
 // @UID
+// @Tenant
 // @PurgeData
 //
 func (api *API) RemoveUser(conf UserConfig) error {
@@ -266,8 +355,12 @@ func (api *API) RemoveUser(conf UserConfig) error {
 	if len(errs) > 0 {
 		return errs[0]
 	}
+	guarded, err := api.guardUser(conf)
+	if err != nil {
+		return err
+	}
 	values.Add("format", "json")
-	_, _, err := api.call("DELETE", "/user", values, true)
+	_, _, err = guarded.call("RemoveUser", "DELETE", "/user", values, true)
 	return err
 }
 
@@ -313,7 +406,7 @@ func (api *API) CreateSubUser(conf SubUserConfig) (*SubUsers, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("PUT", "/user", values, true, "subuser")
+	body, _, err := api.call("CreateSubUser", "PUT", "/user", values, true, "subuser")
 	if err != nil {
 		return nil, err
 	}
@@ -353,7 +446,7 @@ func (api *API) UpdateSubUser(conf SubUserConfig) (*SubUsers, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("POST", "/user", values, true, "subuser")
+	body, _, err := api.call("UpdateSubUser", "POST", "/user", values, true, "subuser")
 	if err != nil {
 		return nil, err
 	}
@@ -388,7 +481,7 @@ func (api *API) RemoveSubUser(conf SubUserConfig) error {
 		return errs[0]
 	}
 	values.Add("format", "json")
-	_, _, err := api.call("DELETE", "/user", values, true, "subuser")
+	_, _, err := api.call("RemoveSubUser", "DELETE", "/user", values, true, "subuser")
 	return err
 }
 
@@ -436,7 +529,7 @@ func (api *API) CreateKey(conf KeyConfig) (*KeysDefinition, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("PUT", "/user", values, true, "key")
+	body, _, err := api.call("CreateKey", "PUT", "/user", values, true, "key")
 	if err != nil {
 		return nil, err
 	}
@@ -470,7 +563,7 @@ func (api *API) RemoveKey(conf KeyConfig) error {
 		return errs[0]
 	}
 	values.Add("format", "json")
-	_, _, err := api.call("DELETE", "/user", values, true, "key")
+	_, _, err := api.call("RemoveKey", "DELETE", "/user", values, true, "key")
 	return err
 }
 
@@ -497,10 +590,8 @@ type BucketConfig struct {
 //
 func (api *API) GetBucket(conf BucketConfig) (Buckets, error) {
 	var (
-		ret     = Buckets{}
-		values  = url.Values{}
-		errs    []error
-		variant interface{}
+		values = url.Values{}
+		errs   []error
 	)
 
 	values, errs = encurl.Translate(conf)
@@ -508,47 +599,61 @@ func (api *API) GetBucket(conf BucketConfig) (Buckets, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("GET", "/bucket", values, true)
+	var ret Buckets
+	_, _, err := api.do(request{
+		operation: "GetBucket",
+		verb:      "GET",
+		route:     "/bucket",
+		args:      values,
+		usePrefix: true,
+		decode: func(r io.Reader) (err error) {
+			ret, err = decodeBuckets(r)
+			return
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
-	if err = json.Unmarshal(body, &variant); err != nil {
+	return ret, nil
+}
+
+// ListBucketNames lists the names of the existing buckets, or of the buckets belonging to uid
+//
+// !! caps:	buckets=read !!
+//
+//@UID
+//
+func (api *API) ListBucketNames(conf BucketConfig) ([]string, error) {
+	buckets, err := api.GetBucket(BucketConfig{UID: conf.UID})
+	if err != nil {
 		return nil, err
 	}
-	if tab, ok := variant.([]interface{}); ok {
-		add := Bucket{}
-		for _, v := range tab {
-			if name, ok := v.(string); ok {
-				if add.Name != "" {
-					ret = append(ret, add)
-					add = Bucket{}
-				}
-				add.Name = name
-			} else {
-				js, errMarshal := json.Marshal(v)
-				if errMarshal != nil {
-					return nil, errMarshal
-				}
-				if add.Stats != nil {
-					ret = append(ret, add)
-					add = Bucket{}
-				}
-				add.Stats = new(Stats)
-				err = json.Unmarshal(js, add.Stats)
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
-		ret = append(ret, add)
-	} else {
-		add := Bucket{}
-		add.Stats = new(Stats)
-		err = json.Unmarshal(body, add.Stats)
-		if err != nil {
-			return nil, err
+	ret := make([]string, 0, len(buckets))
+	for _, bucket := range buckets {
+		ret = append(ret, bucket.Name)
+	}
+	return ret, nil
+}
+
+// ListBucketStats lists the statistics of the existing buckets, of the buckets belonging to uid
+// or of a single bucket
+//
+// !! caps:	buckets=read !!
+//
+//@Bucket
+//@UID
+//
+func (api *API) ListBucketStats(conf BucketConfig) ([]Stats, error) {
+	buckets, err := api.GetBucket(BucketConfig{Bucket: conf.Bucket, UID: conf.UID, Stats: true})
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Stats, 0, len(buckets))
+	for _, bucket := range buckets {
+		if bucket.Stats == nil {
+			return nil, fmt.Errorf("missing stats for bucket %q", bucket.Name)
 		}
-		ret = append(ret, add)
+		ret = append(ret, *bucket.Stats)
 	}
 	return ret, nil
 }
@@ -573,8 +678,12 @@ func (api *API) RemoveBucket(conf BucketConfig) error {
 	if len(errs) > 0 {
 		return errs[0]
 	}
+	guarded, err := api.guardBucket(conf)
+	if err != nil {
+		return err
+	}
 	values.Add("format", "json")
-	_, _, err := api.call("DELETE", "/bucket", values, true)
+	_, _, err = guarded.call("RemoveBucket", "DELETE", "/bucket", values, true)
 	return err
 }
 
@@ -603,7 +712,7 @@ func (api *API) UnlinkBucket(conf BucketConfig) error {
 		return errs[0]
 	}
 	values.Add("format", "json")
-	_, _, err := api.call("POST", "/bucket", values, true)
+	_, _, err := api.call("UnlinkBucket", "POST", "/bucket", values, true)
 	return err
 }
 
@@ -630,7 +739,7 @@ func (api *API) CheckBucket(conf BucketConfig) (string, error) {
 		return "", errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("GET", "/bucket", values, true, "index")
+	body, _, err := api.call("CheckBucket", "GET", "/bucket", values, true, "index")
 	return string(body), err
 }
 
@@ -660,7 +769,7 @@ func (api *API) LinkBucket(conf BucketConfig) error {
 		return errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("PUT", "/bucket", values, true)
+	body, _, err := api.call("LinkBucket", "PUT", "/bucket", values, true)
 	// return string(body), err
 	_ = body
 	return err
@@ -689,8 +798,12 @@ func (api *API) RemoveObject(conf BucketConfig) error {
 	if len(errs) > 0 {
 		return errs[0]
 	}
+	guarded, err := api.guardBucket(conf)
+	if err != nil {
+		return err
+	}
 	values.Add("format", "json")
-	_, _, err := api.call("DELETE", "/bucket", values, true, "object")
+	_, _, err = guarded.call("RemoveObject", "DELETE", "/bucket", values, true, "object")
 	return err
 }
 
@@ -715,7 +828,7 @@ func (api *API) GetBucketPolicy(conf BucketConfig) (*Policy, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("GET", "/bucket", values, true, "policy")
+	body, _, err := api.call("GetBucketPolicy", "GET", "/bucket", values, true, "policy")
 	if err = json.Unmarshal(body, &ret); err != nil {
 		return nil, err
 	}
@@ -748,7 +861,7 @@ func (api *API) GetObjectPolicy(conf BucketConfig) (*Policy, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("GET", "/bucket", values, true, "policy")
+	body, _, err := api.call("GetObjectPolicy", "GET", "/bucket", values, true, "policy")
 	if err = json.Unmarshal(body, &ret); err != nil {
 		return nil, err
 	}
@@ -789,7 +902,7 @@ func (api *API) GetQuotas(conf QuotaConfig) (*Quotas, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("GET", "/user", values, true, "quota")
+	body, _, err := api.call("GetQuotas", "GET", "/user", values, true, "quota")
 	if err = json.Unmarshal(body, &ret); err != nil {
 		return nil, err
 	}
@@ -821,7 +934,7 @@ func (api *API) UpdateQuota(conf QuotaConfig) error {
 		return errs[0]
 	}
 	values.Add("format", "json")
-	_, _, err := api.call("PUT", "/user", values, true, "quota")
+	_, _, err := api.call("UpdateQuota", "PUT", "/user", values, true, "quota")
 	return err
 }
 
@@ -849,10 +962,221 @@ func (api *API) UpdateBuckQuota(conf QuotaConfig) error {
 		return errs[0]
 	}
 	values.Add("format", "json")
-	_, _, err := api.call("PUT", "/bucket", values, true, "quota")
+	_, _, err := api.call("UpdateBuckQuota", "PUT", "/bucket", values, true, "quota")
 	return err
 }
 
+// RateLimitConfig rate limit request
+type RateLimitConfig struct {
+	Scope         string `url:"ratelimit-scope,ifStringIsNotEmpty"` // The scope of the rate limit. The options are user, bucket and anonymous (global only).
+	UID           string `url:"uid,ifStringIsNotEmpty"`             // The user to specify a rate limit
+	Bucket        string `url:"bucket,ifStringIsNotEmpty"`          // The bucket name
+	Global        bool   `url:"global,ifBoolIsTrue"`                // Apply to the global configuration instead of a user or a bucket
+	MaxReadOps    *int64 `url:"max-read-ops,int64IfNotNil"`         // The maximum number of read ops per minute per RGW instance. 0 disables this setting.
+	MaxReadBytes  *int64 `url:"max-read-bytes,int64IfNotNil"`       // The maximum number of read bytes per minute per RGW instance. 0 disables this setting.
+	MaxWriteOps   *int64 `url:"max-write-ops,int64IfNotNil"`        // The maximum number of write ops per minute per RGW instance. 0 disables this setting.
+	MaxWriteBytes *int64 `url:"max-write-bytes,int64IfNotNil"`      // The maximum number of write bytes per minute per RGW instance. 0 disables this setting.
+	Enabled       *bool  `url:"enabled,boolIfNotNil"`               // The enabled option enables the rate limit
+}
+
+func (api *API) getRateLimit(operation string, conf RateLimitConfig, ret interface{}) error {
+	var (
+		values = url.Values{}
+		errs   []error
+	)
+
+	if err := api.requireFeature(FeatureRateLimit); err != nil {
+		return err
+	}
+	values, errs = encurl.Translate(conf)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	values.Add("format", "json")
+	body, _, err := api.call(operation, "GET", "/ratelimit", values, true)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, ret)
+}
+
+func (api *API) setRateLimit(operation string, conf RateLimitConfig) error {
+	var (
+		values = url.Values{}
+		errs   []error
+	)
+
+	if err := api.requireFeature(FeatureRateLimit); err != nil {
+		return err
+	}
+	values, errs = encurl.Translate(conf)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	values.Add("format", "json")
+	_, _, err := api.call(operation, "POST", "/ratelimit", values, true)
+	return err
+}
+
+// GetUserRateLimit returns user's rate limit
+//
+// !! caps:	ratelimit=read !!
+//
+//@UID
+//
+func (api *API) GetUserRateLimit(conf RateLimitConfig) (*RateLimit, error) {
+	var ret struct {
+		UserRateLimit RateLimit `json:"user_ratelimit"`
+	}
+
+	if conf.UID == "" {
+		return nil, errors.New("UID field is required")
+	}
+	if err := api.getRateLimit("GetUserRateLimit", RateLimitConfig{Scope: "user", UID: conf.UID}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret.UserRateLimit, nil
+}
+
+// GetBucketRateLimit returns bucket's rate limit
+//
+// !! caps:	ratelimit=read !!
+//
+//@Bucket
+//
+func (api *API) GetBucketRateLimit(conf RateLimitConfig) (*RateLimit, error) {
+	var ret struct {
+		BucketRateLimit RateLimit `json:"bucket_ratelimit"`
+	}
+
+	if conf.Bucket == "" {
+		return nil, errors.New("Bucket field is required")
+	}
+	if err := api.getRateLimit("GetBucketRateLimit", RateLimitConfig{Scope: "bucket", Bucket: conf.Bucket}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret.BucketRateLimit, nil
+}
+
+// GetGlobalRateLimit returns the global rate limits applied to users, buckets and anonymous requests
+//
+// !! caps:	ratelimit=read !!
+//
+func (api *API) GetGlobalRateLimit() (*GlobalRateLimit, error) {
+	ret := &GlobalRateLimit{}
+
+	if err := api.getRateLimit("GetGlobalRateLimit", RateLimitConfig{Global: true}, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// SetUserRateLimit updates user's rate limit
+//
+// !! caps:	ratelimit=write !!
+//
+//@UID
+//@MaxReadOps
+//@MaxReadBytes
+//@MaxWriteOps
+//@MaxWriteBytes
+//@Enabled
+//
+func (api *API) SetUserRateLimit(conf RateLimitConfig) error {
+	if conf.UID == "" {
+		return errors.New("UID field is required")
+	}
+	conf.Scope = "user"
+	conf.Bucket = ""
+	conf.Global = false
+	return api.setRateLimit("SetUserRateLimit", conf)
+}
+
+// SetBucketRateLimit updates bucket's rate limit
+//
+// !! caps:	ratelimit=write !!
+//
+//@Bucket
+//@MaxReadOps
+//@MaxReadBytes
+//@MaxWriteOps
+//@MaxWriteBytes
+//@Enabled
+//
+func (api *API) SetBucketRateLimit(conf RateLimitConfig) error {
+	if conf.Bucket == "" {
+		return errors.New("Bucket field is required")
+	}
+	conf.Scope = "bucket"
+	conf.UID = ""
+	conf.Global = false
+	return api.setRateLimit("SetBucketRateLimit", conf)
+}
+
+// SetGlobalRateLimit updates the global rate limit of a scope
+//
+// !! caps:	ratelimit=write !!
+//
+//@Scope [user,bucket,anonymous]
+//@MaxReadOps
+//@MaxReadBytes
+//@MaxWriteOps
+//@MaxWriteBytes
+//@Enabled
+//
+func (api *API) SetGlobalRateLimit(conf RateLimitConfig) error {
+	if conf.Scope == "" {
+		return errors.New("Scope field is required")
+	}
+	conf.UID = ""
+	conf.Bucket = ""
+	conf.Global = true
+	return api.setRateLimit("SetGlobalRateLimit", conf)
+}
+
+// EnableRateLimit enables the rate limit of a user, a bucket or a global scope, leaving the limits untouched
+//
+// !! caps:	ratelimit=write !!
+//
+//@UID
+//@Bucket
+//@Global
+//@Scope
+//
+func (api *API) EnableRateLimit(conf RateLimitConfig) error {
+	return api.toggleRateLimit(conf, true)
+}
+
+// DisableRateLimit disables the rate limit of a user, a bucket or a global scope, leaving the limits untouched
+//
+// !! caps:	ratelimit=write !!
+//
+//@UID
+//@Bucket
+//@Global
+//@Scope
+//
+func (api *API) DisableRateLimit(conf RateLimitConfig) error {
+	return api.toggleRateLimit(conf, false)
+}
+
+func (api *API) toggleRateLimit(conf RateLimitConfig, enabled bool) error {
+	toggle := RateLimitConfig{Enabled: &enabled}
+
+	switch {
+	case conf.Global:
+		toggle.Scope = conf.Scope
+		return api.SetGlobalRateLimit(toggle)
+	case conf.UID != "":
+		toggle.UID = conf.UID
+		return api.SetUserRateLimit(toggle)
+	case conf.Bucket != "":
+		toggle.Bucket = conf.Bucket
+		return api.SetBucketRateLimit(toggle)
+	}
+	return errors.New("UID, Bucket or Global field is required")
+}
+
 // CapConfig capability request
 type CapConfig struct {
 	UID      string `url:"uid,ifStringIsNotEmpty"`       // The user ID
@@ -885,7 +1209,7 @@ func (api *API) AddCapability(conf CapConfig) ([]Capability, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("PUT", "/user", values, true, "caps")
+	body, _, err := api.call("AddCapability", "PUT", "/user", values, true, "caps")
 	if err = json.Unmarshal(body, &ret); err != nil {
 		return nil, err
 	}
@@ -918,7 +1242,7 @@ func (api *API) DelCapability(conf CapConfig) ([]Capability, error) {
 		return nil, errs[0]
 	}
 	values.Add("format", "json")
-	body, _, err := api.call("DELETE", "/user", values, true, "caps")
+	body, _, err := api.call("DelCapability", "DELETE", "/user", values, true, "caps")
 	if err = json.Unmarshal(body, &ret); err != nil {
 		return nil, err
 	}
@@ -89,6 +89,18 @@ func TestUser(t *testing.T) {
 		So(user, ShouldNotBeNil)
 	})
 
+	Convey("Testing Get user stats", t, func() {
+		api := createNewAPI()
+
+		stats, err := api.GetUserStats(UserStatsConfig{})
+		So(err, ShouldNotBeNil)
+		So(stats, ShouldBeNil)
+		stats, err = api.SyncUserStats("UnitTest")
+		So(err, ShouldBeNil)
+		So(stats, ShouldNotBeNil)
+		So(stats.NumObjects, ShouldEqual, 0)
+	})
+
 	Convey("Testing Get UIDs", t, func() {
 		api := createNewAPI()
 
@@ -1089,7 +1101,145 @@ func TestQuota(t *testing.T) {
 			Stats:  true,
 		})
 		So(err, ShouldBeNil)
-		So(buckets[0].Stats.BucketQuota.MaxSizeKb, ShouldEqual, 4096)
+		So(buckets[0].Stats.BucketQuota.MaxSizeKb, ShouldEqual, int64(4096))
+	})
+}
+
+func TestRateLimit(t *testing.T) {
+	Convey("Testing RateLimit Without arguments", t, func() {
+		api := createNewAPI()
+
+		limit, err := api.GetUserRateLimit(RateLimitConfig{})
+		So(limit, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+		limit, err = api.GetBucketRateLimit(RateLimitConfig{})
+		So(limit, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+		err = api.SetUserRateLimit(RateLimitConfig{})
+		So(err, ShouldNotBeNil)
+		err = api.SetGlobalRateLimit(RateLimitConfig{})
+		So(err, ShouldNotBeNil)
+		err = api.EnableRateLimit(RateLimitConfig{})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing Set User RateLimit", t, func() {
+		api := createNewAPI()
+		user, err := api.CreateUser(UserConfig{
+			UID:         "UnitTest",
+			DisplayName: "Unit Test",
+		})
+		So(err, ShouldBeNil)
+		So(user, ShouldNotBeNil)
+
+		defer func() {
+			err = api.RemoveUser(UserConfig{
+				UID:       "UnitTest",
+				PurgeData: true,
+			})
+			So(err, ShouldBeNil)
+		}()
+		maxReadOps := int64(1024)
+		err = api.SetUserRateLimit(RateLimitConfig{
+			UID:        "UnitTest",
+			MaxReadOps: &maxReadOps,
+		})
+		So(err, ShouldBeNil)
+		err = api.EnableRateLimit(RateLimitConfig{
+			UID: "UnitTest",
+		})
+		So(err, ShouldBeNil)
+		limit, err := api.GetUserRateLimit(RateLimitConfig{
+			UID: "UnitTest",
+		})
+		So(err, ShouldBeNil)
+		So(limit.MaxReadOps, ShouldEqual, 1024)
+		So(limit.Enabled, ShouldBeTrue)
+		err = api.DisableRateLimit(RateLimitConfig{
+			UID: "UnitTest",
+		})
+		So(err, ShouldBeNil)
+		limit, err = api.GetUserRateLimit(RateLimitConfig{
+			UID: "UnitTest",
+		})
+		So(err, ShouldBeNil)
+		So(limit.MaxReadOps, ShouldEqual, 1024)
+		So(limit.Enabled, ShouldBeFalse)
+	})
+
+	Convey("Testing Get Global RateLimit", t, func() {
+		api := createNewAPI()
+
+		limits, err := api.GetGlobalRateLimit()
+		So(err, ShouldBeNil)
+		So(limits, ShouldNotBeNil)
+	})
+}
+
+func TestAccount(t *testing.T) {
+	Convey("Testing Account without arguments", t, func() {
+		api := createNewAPI()
+
+		account, err := api.CreateAccount(AccountConfig{})
+		So(account, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+		account, err = api.GetAccount(AccountConfig{})
+		So(account, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+		err = api.RemoveAccount(AccountConfig{})
+		So(err, ShouldNotBeNil)
+		err = api.UpdateAccountQuota(AccountQuotaConfig{ID: "RGW00000000000000001"})
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing Create user in account", t, func() {
+		api := createNewAPI()
+		maxUsers := 2
+
+		account, err := api.CreateAccount(AccountConfig{
+			Name:     "UnitTest",
+			MaxUsers: &maxUsers,
+		})
+		So(err, ShouldBeNil)
+		So(account, ShouldNotBeNil)
+		So(account.MaxUsers, ShouldEqual, 2)
+
+		defer func() {
+			err = api.RemoveAccount(AccountConfig{
+				ID: account.ID,
+			})
+			So(err, ShouldBeNil)
+		}()
+		root := true
+		user, err := api.CreateUser(UserConfig{
+			UID:         "UnitTest",
+			DisplayName: "Unit Test",
+			AccountID:   account.ID,
+			AccountRoot: &root,
+		})
+		So(err, ShouldBeNil)
+		So(user.AccountID, ShouldEqual, account.ID)
+		err = api.RemoveUser(UserConfig{
+			UID:       "UnitTest",
+			PurgeData: true,
+		})
+		So(err, ShouldBeNil)
+
+		maxObjects := int64(100)
+		enabled := true
+		err = api.UpdateAccountQuota(AccountQuotaConfig{
+			ID:         account.ID,
+			QuotaType:  "account",
+			MaxObjects: &maxObjects,
+			Enabled:    &enabled,
+		})
+		So(err, ShouldBeNil)
+		account, err = api.GetAccount(AccountConfig{
+			ID: account.ID,
+		})
+		So(err, ShouldBeNil)
+		So(account.Quota.MaxObjects, ShouldEqual, 100)
+		So(account.Quota.Enabled, ShouldBeTrue)
 	})
 }
 
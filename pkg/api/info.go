@@ -0,0 +1,205 @@
+package radosAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sync"
+)
+
+// Features probed by Probe
+const (
+	FeatureInfo      = "info"      // GET /admin/info
+	FeatureRateLimit = "ratelimit" // /admin/ratelimit
+	FeatureAccounts  = "accounts"  // /admin/account
+)
+
+type featureProbe struct {
+	feature string
+	route   string
+	args    url.Values
+	release string // The first release shipping the feature
+}
+
+// featureProbes are sorted from the newest to the oldest feature
+var featureProbes = []featureProbe{
+	{FeatureAccounts, "/account", url.Values{}, "squid"},
+	{FeatureRateLimit, "/ratelimit", url.Values{"global": {"true"}}, "quincy"},
+	{FeatureInfo, "/info", url.Values{}, "octopus"},
+}
+
+var serverRelease = regexp.MustCompile(`\(([a-z]+)\)`)
+
+// adminErrorCodes are the error codes only returned by the admin routes,
+// the gateway answers unknown routes as bucket requests
+var adminErrorCodes = map[string]bool{
+	"InvalidArgument": true,
+	"NoSuchAccount":   true,
+	"NoSuchUser":      true,
+}
+
+// GatewayInfo represents what Probe discovered about the gateway
+type GatewayInfo struct {
+	FSID     string          // The ceph cluster fsid, empty if /admin/info is not available
+	Server   string          // The Server header returned by the gateway
+	Release  string          // The ceph release name, from the Server header or the newest feature found
+	Features map[string]bool // The features supported by the gateway, or not
+	Denied   []string        // The features whose probe was denied for lack of caps, they are missing from Features
+}
+
+// probeState holds the result of the last Probe
+type probeState struct {
+	lock    sync.RWMutex
+	gateway *GatewayInfo
+}
+
+// UnsupportedError is returned when an operation requires a feature the gateway doesn't have
+type UnsupportedError struct {
+	Feature string
+	Release string
+}
+
+func (e *UnsupportedError) Error() string {
+	if e.Release == "" {
+		return fmt.Sprintf("%s unsupported on this gateway", e.Feature)
+	}
+	return fmt.Sprintf("%s unsupported on this gateway (%s)", e.Feature, e.Release)
+}
+
+// IsUnsupported returns true if err is an UnsupportedError
+func IsUnsupported(err error) bool {
+	_, ok := err.(*UnsupportedError)
+	return ok
+}
+
+// GetInfo returns the storage backends of the gateway along with the cluster fsid.
+//
+// !! caps: info=read !!
+func (api *API) GetInfo() (*Info, error) {
+	if err := api.requireFeature(FeatureInfo); err != nil {
+		return nil, err
+	}
+	return api.getInfo("GetInfo")
+}
+
+func (api *API) getInfo(operation string) (*Info, error) {
+	ret := &Info{}
+	values := url.Values{}
+
+	values.Add("format", "json")
+	body, _, err := api.call(operation, "GET", "/info", values, true)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(body, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Probe discovers the fsid, release and features of the gateway and records them on api.
+// Once probed, methods relying on a missing feature fail with an UnsupportedError
+// instead of sending the request. The requests don't depend on the release otherwise,
+// the same parameters are sent to every gateway.
+// The features whose probe is denied for lack of caps are unknown and assumed supported,
+// their methods fail with the error of the gateway.
+//
+// !! caps: info=read ratelimit=read accounts=read !!
+func (api *API) Probe() (*GatewayInfo, error) {
+	ret := &GatewayInfo{Features: map[string]bool{}}
+
+	for _, probe := range featureProbes {
+		supported, known, err := api.probeFeature(probe)
+		if err != nil {
+			return nil, err
+		}
+		if !known {
+			ret.Denied = append(ret.Denied, probe.feature)
+			continue
+		}
+		ret.Features[probe.feature] = supported
+		if supported && ret.Release == "" {
+			ret.Release = probe.release
+		}
+	}
+	if ret.Features[FeatureInfo] {
+		info, err := api.getInfo("Probe")
+		if err != nil {
+			return nil, err
+		}
+		for _, backend := range info.Info.StorageBackends {
+			if backend.Name == "rados" {
+				ret.FSID = backend.ClusterID
+			}
+		}
+	}
+
+	header := http.Header{}
+	if _, _, err := api.do(request{
+		operation: "Probe",
+		verb:      "GET",
+		route:     "/",
+		args:      url.Values{},
+		header:    header,
+	}); err != nil {
+		return nil, err
+	}
+	ret.Server = header.Get("Server")
+	if match := serverRelease.FindStringSubmatch(ret.Server); match != nil {
+		ret.Release = match[1]
+	}
+
+	api.probe.lock.Lock()
+	api.probe.gateway = ret
+	api.probe.lock.Unlock()
+	return ret, nil
+}
+
+// probeFeature returns true if the route of probe answers, or fails with an error code of the admin routes.
+// known is false if the probe was denied, the route may exist or not.
+func (api *API) probeFeature(probe featureProbe) (supported, known bool, err error) {
+	var apiErr apiError
+
+	args := url.Values{"format": {"json"}}
+	for k, v := range probe.args {
+		args[k] = v
+	}
+	body, statusCode, err := api.call("Probe", "GET", probe.route, args, true)
+	switch {
+	case statusCode == 0:
+		return false, false, err
+	case statusCode >= 200 && statusCode <= 299:
+		return true, true, nil
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return false, false, nil
+	}
+	json.Unmarshal(body, &apiErr)
+	return adminErrorCodes[apiErr.Code], true, nil
+}
+
+// Gateway returns the result of the last Probe, nil if the gateway hasn't been probed
+func (api *API) Gateway() *GatewayInfo {
+	api.probe.lock.RLock()
+	defer api.probe.lock.RUnlock()
+	return api.probe.gateway
+}
+
+// Supports returns true if the gateway supports feature.
+// Unprobed gateways, and features whose probe was denied, are assumed supported.
+func (api *API) Supports(feature string) bool {
+	gateway := api.Gateway()
+	if gateway == nil {
+		return true
+	}
+	supported, known := gateway.Features[feature]
+	return supported || !known
+}
+
+func (api *API) requireFeature(feature string) error {
+	if api.Supports(feature) {
+		return nil
+	}
+	return &UnsupportedError{Feature: feature, Release: api.Gateway().Release}
+}
@@ -0,0 +1,73 @@
+package radosAPI
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestProbe(t *testing.T) {
+	Convey("Testing Probe on an old gateway", t, func() {
+		api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Server", "Ceph Object Gateway (jewel)")
+			if r.URL.Path == "/" {
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"Code":"NoSuchBucket"}`)
+		})
+		defer server.Close()
+
+		So(api.Supports(FeatureRateLimit), ShouldBeTrue)
+		gateway, err := api.Probe()
+		So(err, ShouldBeNil)
+		So(gateway.Release, ShouldEqual, "jewel")
+		So(gateway.FSID, ShouldEqual, "")
+		So(api.Supports(FeatureRateLimit), ShouldBeFalse)
+
+		info, err := api.GetInfo()
+		So(info, ShouldBeNil)
+		So(IsUnsupported(err), ShouldBeTrue)
+		So(err.Error(), ShouldEqual, "info unsupported on this gateway (jewel)")
+	})
+
+	Convey("Testing Probe on a recent gateway", t, func() {
+		var signed bool
+
+		api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/":
+				signed = r.Header.Get("Authorization") != ""
+				w.Header().Set("Server", "Ceph Object Gateway")
+			case "/admin/info":
+				fmt.Fprint(w, `{"info":{"storage_backends":[{"name":"rados","cluster_id":"fsid"}]}}`)
+			case "/admin/ratelimit":
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"Code":"AccessDenied"}`)
+			case "/admin/account":
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"Code":"InvalidArgument"}`)
+			}
+		})
+		defer server.Close()
+
+		gateway, err := api.Probe()
+		So(err, ShouldBeNil)
+		So(signed, ShouldBeTrue)
+		So(gateway.Server, ShouldEqual, "Ceph Object Gateway")
+		So(gateway.FSID, ShouldEqual, "fsid")
+		So(gateway.Release, ShouldEqual, "squid")
+		So(gateway.Features, ShouldResemble, map[string]bool{FeatureInfo: true, FeatureAccounts: true})
+		So(gateway.Denied, ShouldResemble, []string{FeatureRateLimit})
+		So(api.Supports(FeatureInfo), ShouldBeTrue)
+		So(api.Supports(FeatureAccounts), ShouldBeTrue)
+
+		// the denied feature is sent, and fails with the error of the gateway
+		So(api.Supports(FeatureRateLimit), ShouldBeTrue)
+		_, err = api.GetGlobalRateLimit()
+		So(IsUnsupported(err), ShouldBeFalse)
+		So(err.Error(), ShouldEqual, "[403]: AccessDenied")
+	})
+}
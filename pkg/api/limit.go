@@ -0,0 +1,84 @@
+package radosAPI
+
+import (
+	"context"
+
+	"golang.org/x/time/rate"
+)
+
+// LimitConfig client side limits of the requests sent to the gateway
+type LimitConfig struct {
+	Rate           float64 // The maximum number of requests per second, 0 removes the limit
+	Burst          int     // The number of requests sent at once before Rate applies, at least 1
+	MaxConcurrency int     // The maximum number of requests waiting for a response, 0 removes the limit
+}
+
+// budget enforces a LimitConfig
+type budget struct {
+	limiter *rate.Limiter
+	slots   chan struct{}
+}
+
+func newBudget(conf LimitConfig) *budget {
+	ret := &budget{}
+
+	if conf.Rate > 0 {
+		burst := conf.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		ret.limiter = rate.NewLimiter(rate.Limit(conf.Rate), burst)
+	}
+	if conf.MaxConcurrency > 0 {
+		ret.slots = make(chan struct{}, conf.MaxConcurrency)
+	}
+	return ret
+}
+
+// acquire waits until a request may be sent, the returned func must be called once it is done
+func (b *budget) acquire(ctx context.Context) (func(), error) {
+	release := func() {}
+
+	if b.slots != nil {
+		select {
+		case b.slots <- struct{}{}:
+			release = func() { <-b.slots }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if b.limiter != nil {
+		if err := b.limiter.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// limits holds the budgets of the read (GET and HEAD) and write requests
+type limits struct {
+	read  *budget
+	write *budget
+}
+
+// SetLimits sets the client side limits of the read (GET and HEAD) and write requests,
+// requests wait until they fit in their budget.
+// It should be called before api is used.
+func (api *API) SetLimits(read, write LimitConfig) {
+	api.limits = &limits{
+		read:  newBudget(read),
+		write: newBudget(write),
+	}
+}
+
+// acquire waits until req fits in its budget
+func (api *API) acquire(req request) (func(), error) {
+	if api.limits == nil {
+		return func() {}, nil
+	}
+	if req.verb == "GET" || req.verb == "HEAD" {
+		return api.limits.read.acquire(api.context())
+	}
+	return api.limits.write.acquire(api.context())
+}
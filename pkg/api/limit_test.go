@@ -0,0 +1,72 @@
+package radosAPI
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLimits(t *testing.T) {
+	var inFlight, maxInFlight int32
+
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		fmt.Fprint(w, `{"user_id":"john"}`)
+	})
+	defer server.Close()
+
+	Convey("Testing max concurrency", t, func() {
+		api.SetLimits(LimitConfig{MaxConcurrency: 2}, LimitConfig{})
+		wg := sync.WaitGroup{}
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				api.GetUser("john")
+			}()
+		}
+		wg.Wait()
+		So(atomic.LoadInt32(&maxInFlight), ShouldEqual, 2)
+	})
+
+	Convey("Testing rate", t, func() {
+		api.SetLimits(LimitConfig{}, LimitConfig{Rate: 20, Burst: 1})
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := api.UpdateUser(UserConfig{UID: "john"})
+			So(err, ShouldBeNil)
+		}
+		So(time.Since(start), ShouldBeGreaterThanOrEqualTo, 100*time.Millisecond)
+
+		start = time.Now()
+		for i := 0; i < 3; i++ {
+			_, err := api.GetUser("john")
+			So(err, ShouldBeNil)
+		}
+		So(time.Since(start), ShouldBeLessThan, 100*time.Millisecond)
+	})
+
+	Convey("Testing canceled requests waiting for their budget", t, func() {
+		api.SetLimits(LimitConfig{Rate: 0.1, Burst: 1}, LimitConfig{})
+		_, err := api.GetUser("john")
+		So(err, ShouldBeNil)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, err = api.WithContext(ctx).GetUser("john")
+		So(err, ShouldNotBeNil)
+	})
+}
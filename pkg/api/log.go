@@ -0,0 +1,96 @@
+package radosAPI
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Logger receives a record of every request sent to the gateway, *slog.Logger satisfies it.
+// Successful requests are reported with Info, failed ones with Error, args are key-value pairs.
+type Logger interface {
+	Info(msg string, args ...interface{})
+	Error(msg string, args ...interface{})
+}
+
+// SetLogger sets the logger used to record the requests, nil disables logging.
+// It should be called before api is used.
+func (api *API) SetLogger(logger Logger) {
+	api.logger = logger
+}
+
+// redacted is the value logged in place of secrets
+const redacted = "REDACTED"
+
+// secretParams are the lower cased query parameters never logged
+var secretParams = map[string]bool{
+	"access-key":           true,
+	"secret-key":           true,
+	"secret":               true,
+	"awsaccesskeyid":       true,
+	"signature":            true,
+	"x-amz-signature":      true,
+	"x-amz-credential":     true,
+	"x-amz-security-token": true,
+	"webidentitytoken":     true,
+	"tokencode":            true,
+}
+
+// urlCredentials matches the user info of an URL, e.g. the credentials of a push endpoint
+var urlCredentials = regexp.MustCompile(`(://)[^/?#@\s"'<>]+(@)`)
+
+// redactValue returns the value of the query parameter key with the secrets redacted
+func redactValue(key, value string) string {
+	if secretParams[strings.ToLower(key)] {
+		return redacted
+	}
+	return urlCredentials.ReplaceAllString(value, "${1}"+redacted+"${2}")
+}
+
+// sanitizeQuery returns the query of a request with the secrets redacted
+func sanitizeQuery(sub string, args url.Values) string {
+	var parts []string
+
+	if sub != "" {
+		key := strings.SplitN(sub, "=", 2)[0]
+		if secretParams[strings.ToLower(key)] {
+			sub = key + "=" + redacted
+		}
+		parts = append(parts, sub)
+	}
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range args[k] {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(redactValue(k, v)))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func (api *API) logRequest(req request, host, route string, statusCode int, latency time.Duration, code string, err error) {
+	if api.logger == nil {
+		return
+	}
+	args := []interface{}{
+		"method", req.verb,
+		"endpoint", host,
+		"route", route,
+		"query", sanitizeQuery(req.sub, req.args),
+		"status", statusCode,
+		"latency", latency,
+	}
+	if err == nil {
+		api.logger.Info("radosgw request", args...)
+		return
+	}
+	if code != "" {
+		args = append(args, "code", code)
+	}
+	api.logger.Error("radosgw request", append(args, "error", err.Error())...)
+}
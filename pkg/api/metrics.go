@@ -0,0 +1,19 @@
+package radosAPI
+
+import "time"
+
+// Metrics receives the measures of every request sent to the gateway.
+// operation is the API method sending the request, such as CreateUser.
+type Metrics interface {
+	// RequestStarted is called before the request is sent
+	RequestStarted(operation string)
+	// RequestDone is called once the response is read. statusCode is 0 if no response was received,
+	// code is the error code returned by the gateway, if any.
+	RequestDone(operation string, statusCode int, code string, latency time.Duration, err error)
+}
+
+// SetMetrics sets the sink of the request measures, nil disables them.
+// It should be called before api is used.
+func (api *API) SetMetrics(metrics Metrics) {
+	api.metrics = metrics
+}
@@ -0,0 +1,133 @@
+package radosAPI
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var thumbprint = regexp.MustCompile(`^[0-9a-fA-F]{40}$`)
+
+// CapsError is returned when the gateway denies an operation the credentials lack caps for
+type CapsError struct {
+	Caps string // The caps required, as given to AddCapability
+	Err  error  // The error returned by the gateway
+}
+
+func (e *CapsError) Error() string {
+	return fmt.Sprintf("%v: the user requires the %s caps", e.Err, e.Caps)
+}
+
+// Unwrap returns the error returned by the gateway
+func (e *CapsError) Unwrap() error {
+	return e.Err
+}
+
+// IsCapsError returns true if err is a CapsError
+func IsCapsError(err error) bool {
+	_, ok := err.(*CapsError)
+	return ok
+}
+
+// capsError turns an AccessDenied error into a CapsError naming caps
+func capsError(err error, caps string) error {
+	if err != nil && strings.HasSuffix(err.Error(), "AccessDenied") {
+		return &CapsError{Caps: caps, Err: err}
+	}
+	return err
+}
+
+// OIDCProviderConfig OpenID Connect provider request
+type OIDCProviderConfig struct {
+	Arn            string   // The ARN of the provider, returned by CreateOpenIDConnectProvider
+	URL            string   // The URL of the identity provider, must begin with https://
+	ClientIDList   []string // The client IDs (audiences) allowed to use the provider
+	ThumbprintList []string // The SHA-1 thumbprints of the provider certificates, 1 to 5 are required
+}
+
+// CreateOpenIDConnectProvider registers an OpenID Connect identity provider and returns its ARN.
+//
+// !! caps: oidc-provider=write !!
+//
+// @URL
+// @ClientIDList
+// @ThumbprintList
+func (api *API) CreateOpenIDConnectProvider(conf OIDCProviderConfig) (string, error) {
+	var ret struct {
+		Arn string `xml:"CreateOpenIDConnectProviderResult>OpenIDConnectProviderArn"`
+	}
+
+	if conf.URL == "" {
+		return "", errors.New("URL field is required")
+	}
+	if len(conf.ThumbprintList) == 0 || len(conf.ThumbprintList) > 5 {
+		return "", errors.New("ThumbprintList field requires 1 to 5 thumbprints")
+	}
+	values := url.Values{"Url": {conf.URL}}
+	for idx, id := range conf.ClientIDList {
+		values.Add(fmt.Sprintf("ClientIDList.member.%d", idx+1), id)
+	}
+	for idx, sum := range conf.ThumbprintList {
+		if !thumbprint.MatchString(sum) {
+			return "", fmt.Errorf("invalid thumbprint %q, should be 40 hexadecimal characters", sum)
+		}
+		values.Add(fmt.Sprintf("ThumbprintList.member.%d", idx+1), sum)
+	}
+	if err := api.iamCall("CreateOpenIDConnectProvider", values, &ret); err != nil {
+		return "", capsError(err, "oidc-provider=write")
+	}
+	return ret.Arn, nil
+}
+
+// GetOpenIDConnectProvider gets OpenID Connect provider information.
+//
+// !! caps: oidc-provider=read !!
+//
+// @Arn
+func (api *API) GetOpenIDConnectProvider(conf OIDCProviderConfig) (*OpenIDConnectProvider, error) {
+	var ret struct {
+		Provider OpenIDConnectProvider `xml:"GetOpenIDConnectProviderResult"`
+	}
+
+	if conf.Arn == "" {
+		return nil, errors.New("Arn field is required")
+	}
+	if err := api.iamCall("GetOpenIDConnectProvider", url.Values{
+		"OpenIDConnectProviderArn": {conf.Arn},
+	}, &ret); err != nil {
+		return nil, capsError(err, "oidc-provider=read")
+	}
+	ret.Provider.Arn = conf.Arn
+	return &ret.Provider, nil
+}
+
+// ListOpenIDConnectProviders lists the ARNs of the OpenID Connect providers.
+//
+// !! caps: oidc-provider=read !!
+func (api *API) ListOpenIDConnectProviders() ([]string, error) {
+	var ret struct {
+		Arns []string `xml:"ListOpenIDConnectProvidersResult>OpenIDConnectProviderList>member>Arn"`
+	}
+
+	if err := api.iamCall("ListOpenIDConnectProviders", url.Values{}, &ret); err != nil {
+		return nil, capsError(err, "oidc-provider=read")
+	}
+	return ret.Arns, nil
+}
+
+// DeleteOpenIDConnectProvider removes an OpenID Connect provider.
+//
+// !! caps: oidc-provider=write !!
+//
+// @Arn
+func (api *API) DeleteOpenIDConnectProvider(conf OIDCProviderConfig) error {
+	if conf.Arn == "" {
+		return errors.New("Arn field is required")
+	}
+	err := api.iamCall("DeleteOpenIDConnectProvider", url.Values{
+		"OpenIDConnectProviderArn": {conf.Arn},
+	}, nil)
+	return capsError(err, "oidc-provider=write")
+}
@@ -0,0 +1,64 @@
+package radosAPI
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOpenIDConnectProvider(t *testing.T) {
+	fingerprint := strings.Repeat("a1", 20)
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch query.Get("Action") {
+		case "CreateOpenIDConnectProvider":
+			fmt.Fprintf(w, `<CreateOpenIDConnectProviderResponse><CreateOpenIDConnectProviderResult>`+
+				`<OpenIDConnectProviderArn>arn:aws:iam:::oidc-provider/%s</OpenIDConnectProviderArn>`+
+				`</CreateOpenIDConnectProviderResult></CreateOpenIDConnectProviderResponse>`, query.Get("Url")[8:])
+		case "ListOpenIDConnectProviders":
+			fmt.Fprint(w, `<ListOpenIDConnectProvidersResponse><ListOpenIDConnectProvidersResult><OpenIDConnectProviderList>`+
+				`<member><Arn>arn:aws:iam:::oidc-provider/idp.example.com</Arn></member>`+
+				`</OpenIDConnectProviderList></ListOpenIDConnectProvidersResult></ListOpenIDConnectProvidersResponse>`)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<ErrorResponse><Error><Code>AccessDenied</Code></Error></ErrorResponse>`)
+		}
+	})
+	defer server.Close()
+
+	Convey("Testing CreateOpenIDConnectProvider with invalid thumbprints", t, func() {
+		arn, err := api.CreateOpenIDConnectProvider(OIDCProviderConfig{URL: "https://idp.example.com"})
+		So(arn, ShouldEqual, "")
+		So(err, ShouldNotBeNil)
+		arn, err = api.CreateOpenIDConnectProvider(OIDCProviderConfig{
+			URL:            "https://idp.example.com",
+			ThumbprintList: []string{"abc"},
+		})
+		So(arn, ShouldEqual, "")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing Create and List OpenIDConnectProviders", t, func() {
+		arn, err := api.CreateOpenIDConnectProvider(OIDCProviderConfig{
+			URL:            "https://idp.example.com",
+			ClientIDList:   []string{"app"},
+			ThumbprintList: []string{fingerprint},
+		})
+		So(err, ShouldBeNil)
+		So(arn, ShouldEqual, "arn:aws:iam:::oidc-provider/idp.example.com")
+		arns, err := api.ListOpenIDConnectProviders()
+		So(err, ShouldBeNil)
+		So(arns, ShouldResemble, []string{arn})
+	})
+
+	Convey("Testing DeleteOpenIDConnectProvider without caps", t, func() {
+		err := api.DeleteOpenIDConnectProvider(OIDCProviderConfig{Arn: "arn:aws:iam:::oidc-provider/idp.example.com"})
+		So(IsCapsError(err), ShouldBeTrue)
+		So(err.Error(), ShouldEqual, "[403]: AccessDenied: the user requires the oidc-provider=write caps")
+		So(errors.Unwrap(err).Error(), ShouldEqual, "[403]: AccessDenied")
+	})
+}
@@ -0,0 +1,252 @@
+package radosAPI
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PlannedRequest represents a request recorded instead of being sent, see WithDryRun
+type PlannedRequest struct {
+	Method  string
+	Route   string     // The route, with the admin prefix
+	Sub     string     // The subresource, sent before Args
+	Args    url.Values // The arguments, as encoded from the config of the call
+	Payload []byte
+}
+
+// Query returns the query the request would be sent with
+func (r PlannedRequest) Query() string {
+	if r.Sub == "" {
+		return r.Args.Encode()
+	}
+	return r.Sub + "&" + r.Args.Encode()
+}
+
+// String formats the request with its secrets redacted
+func (r PlannedRequest) String() string {
+	return fmt.Sprintf("%s %s?%s", r.Method, r.Route, sanitizeQuery(r.Sub, r.Args))
+}
+
+// Plan records the requests of the calls made in dry run mode, it is safe for concurrent use
+type Plan struct {
+	lock     sync.Mutex
+	requests []PlannedRequest
+}
+
+// Requests returns the recorded requests, in the order they were made
+func (p *Plan) Requests() []PlannedRequest {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]PlannedRequest{}, p.requests...)
+}
+
+func (p *Plan) record(req PlannedRequest) {
+	p.lock.Lock()
+	p.requests = append(p.requests, req)
+	p.lock.Unlock()
+}
+
+// WithDryRun returns a copy of api recording the requests of its mutating calls in plan instead of sending them.
+// The configs are validated as usual and the reads are still sent. The mutating calls return a result
+// synthesized from their config: a created user only holds the fields of UserConfig, a created role
+// or topic gets an ARN built from its name, and so on. AddCapability returns only the added caps
+// and DelCapability none, not the caps the user would keep.
+func (api *API) WithDryRun(plan *Plan) *API {
+	clone := *api
+	clone.plan = plan
+	return &clone
+}
+
+// DryRun returns true if api records its mutating calls in a Plan instead of sending them
+func (api *API) DryRun() bool {
+	return api.plan != nil
+}
+
+// isMutation returns true if req changes the state of the gateway.
+// IAM and STS requests are all sent with POST, their action tells the reads.
+func isMutation(req request) bool {
+	if req.verb == "GET" || req.verb == "HEAD" {
+		return false
+	}
+	action := req.args.Get("Action")
+	for _, prefix := range []string{"Get", "List", "Assume"} {
+		if strings.HasPrefix(action, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+// planRequest records and logs req instead of sending it, and returns a synthesized response
+func (api *API) planRequest(req request, route string) ([]byte, int, error) {
+	planned := PlannedRequest{
+		Method:  req.verb,
+		Route:   route,
+		Sub:     req.sub,
+		Args:    req.args,
+		Payload: req.payload,
+	}
+	api.plan.record(planned)
+	if api.logger != nil {
+		api.logger.Info("radosgw dry run", "method", req.verb, "route", route, "query", sanitizeQuery(req.sub, req.args))
+	}
+	body, err := synthesize(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, http.StatusOK, nil
+}
+
+// permissions translates the access of SubUserConfig to the permissions returned by the gateway
+var permissions = map[string]string{
+	"read":      "read",
+	"write":     "write",
+	"readwrite": "read-write",
+	"full":      "full-control",
+}
+
+// noResult are the IAM and SNS actions whose response is not decoded
+var noResult = map[string]bool{
+	"DeleteOpenIDConnectProvider": true,
+	"DeleteRole":                  true,
+	"UpdateAssumeRolePolicy":      true,
+	"PutRolePolicy":               true,
+	"DeleteRolePolicy":            true,
+	"TagRole":                     true,
+	"UntagRole":                   true,
+	"PutUserPolicy":               true,
+	"DeleteUserPolicy":            true,
+	"DeleteTopic":                 true,
+}
+
+// synthesizeAction returns the XML response of an IAM or SNS action, built from its arguments
+func synthesizeAction(action string, args url.Values) ([]byte, error) {
+	switch action {
+	case "CreateRole":
+		path := args.Get("Path")
+		if path == "" {
+			path = "/"
+		}
+		role := Role{
+			RoleName:                 args.Get("RoleName"),
+			Path:                     path,
+			Arn:                      "arn:aws:iam:::role" + path + args.Get("RoleName"),
+			AssumeRolePolicyDocument: args.Get("AssumeRolePolicyDocument"),
+			MaxSessionDuration:       3600,
+		}
+		if duration := args.Get("MaxSessionDuration"); duration != "" {
+			role.MaxSessionDuration, _ = strconv.Atoi(duration)
+		}
+		for idx := 1; args.Get(fmt.Sprintf("Tags.member.%d.Key", idx)) != ""; idx++ {
+			role.Tags = append(role.Tags, Tag{
+				Key:   args.Get(fmt.Sprintf("Tags.member.%d.Key", idx)),
+				Value: args.Get(fmt.Sprintf("Tags.member.%d.Value", idx)),
+			})
+		}
+		return xml.Marshal(struct {
+			XMLName xml.Name `xml:"CreateRoleResponse"`
+			Role    Role     `xml:"CreateRoleResult>Role"`
+		}{Role: role})
+	case "CreateOpenIDConnectProvider":
+		return xml.Marshal(struct {
+			XMLName xml.Name `xml:"CreateOpenIDConnectProviderResponse"`
+			Arn     string   `xml:"CreateOpenIDConnectProviderResult>OpenIDConnectProviderArn"`
+		}{Arn: "arn:aws:iam:::oidc-provider/" + strings.TrimPrefix(args.Get("Url"), "https://")})
+	case "CreateTopic":
+		return xml.Marshal(struct {
+			XMLName  xml.Name `xml:"CreateTopicResponse"`
+			TopicArn string   `xml:"CreateTopicResult>TopicArn"`
+		}{TopicArn: "arn:aws:sns:::" + args.Get("Name")})
+	}
+	if noResult[action] {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%s is not plannable", action)
+}
+
+// synthesize returns the response of a mutating request, built from its arguments.
+// The requests whose response is unknown are not plannable.
+func synthesize(req request) ([]byte, error) {
+	if action := req.args.Get("Action"); action != "" {
+		return synthesizeAction(action, req.args)
+	}
+	if !req.usePrefix {
+		// the bucket notifications, sent through the S3 API
+		if strings.HasPrefix(req.sub, "notification") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%s is not plannable", req.operation)
+	}
+	args := req.args
+	uid := args.Get("uid")
+	switch req.route + "?" + req.sub {
+	case "/user?":
+		if req.verb == "DELETE" {
+			return nil, nil
+		}
+		user := User{
+			UserID:      uid,
+			Tenant:      args.Get("tenant"),
+			DisplayName: args.Get("display-name"),
+			Email:       args.Get("email"),
+			OpMask:      args.Get("op-mask"),
+			Suspended:   args.Get("suspended") == "True",
+		}
+		user.MaxBuckets, _ = strconv.Atoi(args.Get("max-buckets"))
+		if args.Get("access-key") != "" {
+			user.Keys = KeysDefinition{{AccessKey: args.Get("access-key"), SecretKey: args.Get("secret-key"), User: uid}}
+		}
+		return json.Marshal(user)
+	case "/user?subuser":
+		if req.verb == "DELETE" {
+			return nil, nil
+		}
+		id := args.Get("subuser")
+		if !strings.Contains(id, ":") {
+			id = uid + ":" + id
+		}
+		return json.Marshal(SubUsers{{ID: id, Permissions: permissions[args.Get("access")]}})
+	case "/user?key":
+		if req.verb == "DELETE" {
+			return nil, nil
+		}
+		owner := uid
+		if subuser := args.Get("subuser"); subuser != "" {
+			owner = subuser
+		}
+		return json.Marshal(KeysDefinition{{AccessKey: args.Get("access-key"), SecretKey: args.Get("secret-key"), User: owner}})
+	case "/user?caps":
+		// the gateway returns every cap of the user, only the ones of the request are known here
+		caps := []Capability{}
+		if req.verb == "PUT" {
+			for _, capability := range strings.Split(args.Get("user-caps"), ";") {
+				tab := strings.SplitN(capability, "=", 2)
+				if len(tab) == 2 {
+					caps = append(caps, Capability{Type: strings.TrimSpace(tab[0]), Perm: strings.TrimSpace(tab[1])})
+				}
+			}
+		}
+		return json.Marshal(caps)
+	case "/account?":
+		if req.verb == "DELETE" {
+			return nil, nil
+		}
+		account := Account{
+			ID:     args.Get("id"),
+			Name:   args.Get("name"),
+			Email:  args.Get("email"),
+			Tenant: args.Get("tenant"),
+		}
+		return json.Marshal(account)
+	case "/usage?", "/bucket?", "/bucket?object", "/user?quota", "/bucket?quota", "/ratelimit?", "/account?quota":
+		// the response is not decoded
+		return nil, nil
+	}
+	return nil, fmt.Errorf("%s is not plannable", req.operation)
+}
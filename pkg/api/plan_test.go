@@ -0,0 +1,143 @@
+package radosAPI
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDryRun(t *testing.T) {
+	var sent []string
+
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		sent = append(sent, r.Method+" "+r.URL.Path)
+		fmt.Fprint(w, `{"user_id":"john","display_name":"John"}`)
+	})
+	defer server.Close()
+
+	Convey("Testing the dry run mode", t, func() {
+		sent = nil
+		plan := &Plan{}
+		dry := api.WithDryRun(plan)
+		So(api.DryRun(), ShouldBeFalse)
+		So(dry.DryRun(), ShouldBeTrue)
+
+		_, err := dry.CreateUser(UserConfig{DisplayName: "John"})
+		So(err, ShouldNotBeNil)
+		So(plan.Requests(), ShouldBeEmpty)
+
+		user, err := dry.CreateUser(UserConfig{UID: "john", DisplayName: "John", AccessKey: "AK", SecretKey: "SK"})
+		So(err, ShouldBeNil)
+		So(user.UserID, ShouldEqual, "john")
+		So(user.Keys[0].AccessKey, ShouldEqual, "AK")
+
+		subusers, err := dry.CreateSubUser(SubUserConfig{UID: "john", SubUser: "swift", Access: "full"})
+		So(err, ShouldBeNil)
+		So((*subusers)[0].ID, ShouldEqual, "john:swift")
+		So((*subusers)[0].Permissions, ShouldEqual, "full-control")
+
+		keys, err := dry.CreateKey(KeyConfig{UID: "john", KeyType: "s3", AccessKey: "AK2", SecretKey: "SK2"})
+		So(err, ShouldBeNil)
+		So((*keys)[0].User, ShouldEqual, "john")
+
+		caps, err := dry.AddCapability(CapConfig{UID: "john", UserCaps: "users=read;buckets=*"})
+		So(err, ShouldBeNil)
+		So(caps, ShouldResemble, []Capability{{Type: "users", Perm: "read"}, {Type: "buckets", Perm: "*"}})
+
+		So(dry.UpdateQuota(QuotaConfig{UID: "john", QuotaType: "user", MaxObjects: "100", Enabled: "true"}), ShouldBeNil)
+		So(dry.RemoveKey(KeyConfig{UID: "john", KeyType: "s3", AccessKey: "AK2"}), ShouldBeNil)
+
+		role, err := dry.CreateRole(RoleConfig{RoleName: "admin", AssumeRolePolicyDocument: "{}", Tags: []Tag{{Key: "team", Value: "storage"}}})
+		So(err, ShouldBeNil)
+		So(role.RoleName, ShouldEqual, "admin")
+		So(role.Arn, ShouldEqual, "arn:aws:iam:::role/admin")
+		So(role.AssumeRolePolicyDocument, ShouldEqual, "{}")
+		So(role.Tags, ShouldResemble, []Tag{{Key: "team", Value: "storage"}})
+
+		So(sent, ShouldBeEmpty)
+		_, err = dry.GetUser("john")
+		So(err, ShouldBeNil)
+		So(sent, ShouldResemble, []string{"GET /admin/user"})
+
+		requests := plan.Requests()
+		So(len(requests), ShouldEqual, 7)
+		So(requests[0].Method, ShouldEqual, "PUT")
+		So(requests[0].Route, ShouldEqual, "/admin/user")
+		So(requests[0].Query(), ShouldEqual, "access-key=AK&display-name=John&format=json&secret-key=SK&uid=john")
+		So(requests[0].String(), ShouldEqual, "PUT /admin/user?access-key=REDACTED&display-name=John&format=json&secret-key=REDACTED&uid=john")
+		So(requests[4].Query(), ShouldStartWith, "quota&")
+		So(requests[5].Method, ShouldEqual, "DELETE")
+		So(requests[6].Args.Get("Action"), ShouldEqual, "CreateRole")
+
+		arn, err := dry.CreateTopic(TopicConfig{Name: "uploads"})
+		So(err, ShouldBeNil)
+		So(arn, ShouldEqual, "arn:aws:sns:::uploads")
+		arn, err = dry.CreateOpenIDConnectProvider(OIDCProviderConfig{URL: "https://idp.example.com", ThumbprintList: []string{strings.Repeat("a1", 20)}})
+		So(err, ShouldBeNil)
+		So(arn, ShouldEqual, "arn:aws:iam:::oidc-provider/idp.example.com")
+		So(dry.PutRolePolicy(RoleConfig{RoleName: "admin", PolicyName: "s3", PolicyDocument: "{}"}), ShouldBeNil)
+		So(sent, ShouldHaveLength, 1)
+		So(len(plan.Requests()), ShouldEqual, 10)
+
+		_, err = api.CreateUser(UserConfig{UID: "john", DisplayName: "John"})
+		So(err, ShouldBeNil)
+		So(len(sent), ShouldEqual, 2)
+	})
+
+	Convey("Testing requests without a synthesized result are not plannable", t, func() {
+		_, err := synthesize(request{operation: "PutMetadata", verb: "PUT", route: "/metadata/user", usePrefix: true, args: url.Values{}})
+		So(err.Error(), ShouldEqual, "PutMetadata is not plannable")
+		_, err = synthesize(request{verb: "POST", route: "/", args: url.Values{"Action": {"CreateUser"}}})
+		So(err.Error(), ShouldEqual, "CreateUser is not plannable")
+	})
+
+	Convey("Testing the dry run of an import", t, func() {
+		var sent []string
+
+		api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+			sent = append(sent, r.Method+" "+r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"Code":"NoSuchUser"}`)
+		})
+		defer server.Close()
+		plan := &Plan{}
+
+		report, err := api.WithDryRun(plan).ImportUsers(&Archive{Users: []ArchivedUser{{
+			User: User{
+				UserID:      "john",
+				DisplayName: "John",
+				Keys:        KeysDefinition{{AccessKey: "AK", SecretKey: "SK", User: "john"}},
+				Subusers:    SubUsers{{ID: "john:swift", Permissions: "read"}},
+				Caps:        []Capability{{Type: "users", Perm: "read"}},
+			},
+		}}}, ImportConfig{})
+		So(err, ShouldBeNil)
+		So(report.Failed, ShouldBeEmpty)
+		So(report.Created, ShouldResemble, []string{"john"})
+		So(sent, ShouldResemble, []string{"GET /admin/user"})
+		operations := []string{}
+		for _, planned := range plan.Requests() {
+			operations = append(operations, planned.Method+" "+planned.Query()[:strings.Index(planned.Query(), "&")])
+		}
+		So(operations, ShouldResemble, []string{"PUT access-key=AK", "PUT subuser", "PUT key", "PUT caps"})
+	})
+
+	Convey("Testing the reads of IAM and STS", t, func() {
+		So(isMutation(request{verb: "POST"}), ShouldBeTrue)
+		So(isMutation(request{verb: "GET"}), ShouldBeFalse)
+		for action, mutation := range map[string]bool{
+			"CreateRole":      true,
+			"TagRole":         true,
+			"ListRoles":       false,
+			"GetRolePolicy":   false,
+			"AssumeRole":      false,
+			"GetSessionToken": false,
+		} {
+			So(isMutation(request{verb: "POST", args: map[string][]string{"Action": {action}}}), ShouldEqual, mutation)
+		}
+	})
+}
@@ -0,0 +1,232 @@
+package radosAPI
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RecorderMode selects what a Recorder does with the requests
+type RecorderMode int
+
+const (
+	RecordMode RecorderMode = iota // Send the requests and record them with their responses
+	ReplayMode                     // Answer the requests with the recorded responses, without sending them
+)
+
+// Interaction represents a recorded request and its response
+type Interaction struct {
+	Method      string      `json:"method"`
+	Path        string      `json:"path"`
+	Query       string      `json:"query"`                  // Normalized by sorting its parameters, secrets are redacted
+	RequestBody string      `json:"request_body,omitempty"` // Secrets are redacted
+	StatusCode  int         `json:"status_code"`
+	Header      http.Header `json:"header,omitempty"` // The response headers, without the cookies and authentication ones
+	Body        string      `json:"body"`             // Secrets are redacted
+}
+
+// Recorder is an http.RoundTripper recording the interactions with the gateway to a fixture file,
+// or replaying them, to be used with NewWithClient.
+// Requests are matched by method, path, normalized query and body, the host is ignored,
+// identical requests are answered in the recorded order.
+// The headers of the requests, which carry the signatures, are not recorded.
+type Recorder struct {
+	mode      RecorderMode
+	path      string
+	transport http.RoundTripper
+
+	lock         sync.Mutex
+	interactions []Interaction
+	played       []bool
+}
+
+// secretFields matches the secrets of the JSON and XML bodies
+var secretFields = []*regexp.Regexp{
+	regexp.MustCompile(`("secret_key"\s*:\s*")[^"]*(")`),
+	regexp.MustCompile(`(<SecretAccessKey>)[^<]*(</SecretAccessKey>)`),
+	regexp.MustCompile(`(<SessionToken>)[^<]*(</SessionToken>)`),
+	urlCredentials,
+}
+
+// unrecordedHeaders are the response headers left out of the fixtures, the length of the body
+// changes once its secrets are redacted
+var unrecordedHeaders = []string{
+	"Set-Cookie",
+	"Authorization",
+	"Proxy-Authorization",
+	"WWW-Authenticate",
+	"Proxy-Authenticate",
+	"X-Amz-Security-Token",
+	"Content-Length",
+}
+
+// NewRecorder returns a Recorder of the fixture file path.
+// In ReplayMode the file is read, in RecordMode the requests are sent with transport,
+// or http.DefaultTransport if nil, and Save writes the file.
+func NewRecorder(path string, mode RecorderMode, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	ret := &Recorder{mode: mode, path: path, transport: transport, interactions: []Interaction{}}
+
+	if mode != ReplayMode {
+		return ret, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &ret.interactions); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	ret.played = make([]bool, len(ret.interactions))
+	return ret, nil
+}
+
+// normalizeQuery sorts the parameters of a raw query and redacts the secrets
+func normalizeQuery(raw string) string {
+	parts := []string{}
+
+	for _, part := range strings.Split(raw, "&") {
+		if part == "" {
+			continue
+		}
+		tab := strings.SplitN(part, "=", 2)
+		key, err := url.QueryUnescape(tab[0])
+		if err != nil {
+			key = tab[0]
+		}
+		if len(tab) == 1 {
+			parts = append(parts, url.QueryEscape(key))
+			continue
+		}
+		value, err := url.QueryUnescape(tab[1])
+		if err != nil {
+			value = tab[1]
+		}
+		parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(redactValue(key, value)))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "&")
+}
+
+// scrub redacts the secrets of a response body
+func scrub(body []byte) string {
+	for _, field := range secretFields {
+		body = field.ReplaceAll(body, []byte("${1}"+redacted+"${2}"))
+	}
+	return string(body)
+}
+
+// RoundTrip records or replays req
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	query := normalizeQuery(req.URL.RawQuery)
+	payload := []byte{}
+
+	if req.Body != nil {
+		var err error
+		if payload, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = ioutil.NopCloser(bytes.NewReader(payload))
+	}
+	if r.mode == ReplayMode {
+		return r.replay(req, query, scrub(payload))
+	}
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	header := resp.Header.Clone()
+	for _, name := range unrecordedHeaders {
+		header.Del(name)
+	}
+	r.lock.Lock()
+	r.interactions = append(r.interactions, Interaction{
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		Query:       query,
+		RequestBody: scrub(payload),
+		StatusCode:  resp.StatusCode,
+		Header:      header,
+		Body:        scrub(body),
+	})
+	r.lock.Unlock()
+	return resp, nil
+}
+
+// replay answers req with the first interaction not played yet matching it
+func (r *Recorder) replay(req *http.Request, query, payload string) (*http.Response, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for idx, interaction := range r.interactions {
+		if r.played[idx] || interaction.Method != req.Method || interaction.Path != req.URL.Path ||
+			interaction.Query != query || interaction.RequestBody != payload {
+			continue
+		}
+		r.played[idx] = true
+		header := interaction.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+			StatusCode:    interaction.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(strings.NewReader(interaction.Body)),
+			ContentLength: int64(len(interaction.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("unexpected request %s %s?%s", req.Method, req.URL.Path, query)
+}
+
+// Unplayed returns the recorded interactions which were not replayed
+func (r *Recorder) Unplayed() []Interaction {
+	ret := []Interaction{}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for idx, interaction := range r.interactions {
+		if idx < len(r.played) && !r.played[idx] {
+			ret = append(ret, interaction)
+		}
+	}
+	return ret
+}
+
+// Save writes the recorded interactions to the fixture file, it does nothing in ReplayMode
+func (r *Recorder) Save() error {
+	if r.mode == ReplayMode {
+		return nil
+	}
+	buffer := &bytes.Buffer{}
+	enc := json.NewEncoder(buffer)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	r.lock.Lock()
+	err := enc.Encode(r.interactions)
+	r.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, buffer.Bytes(), 0644)
+}
@@ -1,9 +1,25 @@
 package radosAPI
 
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+)
+
 type apiError struct {
 	Code string `json:"Code"`
 }
 
+// iamError is the XML error returned by the IAM and STS APIs, either
+// <ErrorResponse><Error><Code> or <Error><Code>
+type iamError struct {
+	Code   string `xml:"Code"`
+	Nested string `xml:"Error>Code"`
+}
+
 type Entry struct {
 	Buckets []struct {
 		Bucket     string `json:"bucket"`
@@ -58,43 +74,120 @@ type KeysDefinition []struct {
 
 // User represents the response of user requests
 type User struct {
-	Caps        []Capability   `json:"caps"`
-	DisplayName string         `json:"display_name"`
-	Email       string         `json:"email"`
-	Keys        KeysDefinition `json:"keys"`
-	MaxBuckets  int            `json:"max_buckets"`
-	Subusers    SubUsers       `json:"subusers"`
-	Suspended   int            `json:"suspended"`
-	SwiftKeys   KeysDefinition `json:"swift_keys"`
-	UserID      string         `json:"user_id"`
+	Caps                []Capability   `json:"caps"`
+	DisplayName         string         `json:"display_name"`
+	Email               string         `json:"email"`
+	Keys                KeysDefinition `json:"keys"`
+	MaxBuckets          int            `json:"max_buckets"`
+	Subusers            SubUsers       `json:"subusers"`
+	Suspended           bool           `json:"suspended"`
+	SwiftKeys           KeysDefinition `json:"swift_keys"`
+	UserID              string         `json:"user_id"`
+	Tenant              string         `json:"tenant,omitempty"`
+	AccountID           string         `json:"account_id,omitempty"`
+	OpMask              string         `json:"op_mask"`
+	DefaultPlacement    string         `json:"default_placement"`
+	DefaultStorageClass string         `json:"default_storage_class"`
+	PlacementTags       []string       `json:"placement_tags"`
+	BucketQuota         QuotaInfo      `json:"bucket_quota"`
+	UserQuota           QuotaInfo      `json:"user_quota"`
+	TempURLKeys         []struct {
+		Key int    `json:"key"`
+		Val string `json:"val"`
+	} `json:"temp_url_keys"`
+	Type       string   `json:"type"`
+	MfaIDs     []string `json:"mfa_ids"`
+	CreateDate string   `json:"create_date,omitempty"`
+}
+
+// UnmarshalJSON decodes suspended as returned by the gateway (0 or 1) as well as a boolean
+func (u *User) UnmarshalJSON(data []byte) error {
+	type user User
+	aux := struct {
+		*user
+		Suspended interface{} `json:"suspended"`
+	}{user: (*user)(u)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	switch suspended := aux.Suspended.(type) {
+	case float64:
+		u.Suspended = suspended != 0
+	case bool:
+		u.Suspended = suspended
+	case nil:
+		u.Suspended = false
+	default:
+		return fmt.Errorf("invalid suspended value %v", suspended)
+	}
+	return nil
+}
+
+// MarshalJSON encodes suspended as the gateway does (0 or 1)
+func (u User) MarshalJSON() ([]byte, error) {
+	type user User
+	aux := struct {
+		user
+		Suspended int `json:"suspended"`
+	}{user: user(u)}
+
+	if u.Suspended {
+		aux.Suspended = 1
+	}
+	return json.Marshal(aux)
 }
 
 type Stats struct {
-	Bucket        string `json:"bucket"`
-	NumShards     int    `json:"num_shards"`
-	PlacementRule string `json:"placement_rule"`
-	BucketQuota   struct {
-		Enabled    bool `json:"enabled"`
-		MaxObjects int  `json:"max_objects"`
-		MaxSizeKb  int  `json:"max_size_kb"`
-	} `json:"bucket_quota"`
-	ID        string `json:"id"`
-	IndexPool string `json:"index_pool"`
-	Marker    string `json:"marker"`
-	MasterVer string `json:"master_ver"`
-	MaxMarker string `json:"max_marker"`
-	Mtime     string `json:"mtime"`
-	Owner     string `json:"owner"`
-	Pool      string `json:"pool"`
-	Usage     struct {
-		RgwMain struct {
-			NumObjects   int `json:"num_objects"`
-			SizeKb       int `json:"size_kb"`
-			SizeKbActual int `json:"size_kb_actual"`
-		} `json:"rgw.main"`
-	} `json:"usage"`
-	Ver       string `json:"ver"`
-	ZoneGroup string `json:"zonegroup"`
+	Bucket            string `json:"bucket"`
+	Tenant            string `json:"tenant"`
+	NumShards         int    `json:"num_shards"`
+	PlacementRule     string `json:"placement_rule"`
+	ExplicitPlacement struct {
+		DataPool      string `json:"data_pool"`
+		DataExtraPool string `json:"data_extra_pool"`
+		IndexPool     string `json:"index_pool"`
+	} `json:"explicit_placement"`
+	BucketQuota       QuotaInfo               `json:"bucket_quota"`
+	ID                string                  `json:"id"`
+	IndexPool         string                  `json:"index_pool"`
+	Marker            string                  `json:"marker"`
+	MasterVer         string                  `json:"master_ver"`
+	MaxMarker         string                  `json:"max_marker"`
+	Mtime             string                  `json:"mtime"`
+	CreationTime      string                  `json:"creation_time"`
+	Owner             string                  `json:"owner"`
+	Pool              string                  `json:"pool"`
+	Usage             map[string]StorageStats `json:"usage"`
+	Ver               string                  `json:"ver"`
+	Versioning        string                  `json:"versioning"`
+	ObjectLockEnabled bool                    `json:"object_lock_enabled"`
+	MfaEnabled        bool                    `json:"mfa_enabled"`
+	ZoneGroup         string                  `json:"zonegroup"`
+}
+
+// Usage categories of a bucket
+const (
+	UsageMain        = "rgw.main"        // The objects
+	UsageMultimeta   = "rgw.multimeta"   // The metadata of incomplete multipart uploads
+	UsageNone        = "rgw.none"        // The index entries without objects, such as delete markers
+	UsageCloudTiered = "rgw.cloudtiered" // The objects transitioned to a cloud storage class
+)
+
+// TotalUsage sums the usage of all categories
+func (s *Stats) TotalUsage() StorageStats {
+	total := StorageStats{}
+
+	for _, usage := range s.Usage {
+		total.Size += usage.Size
+		total.SizeActual += usage.SizeActual
+		total.SizeUtilized += usage.SizeUtilized
+		total.SizeKb += usage.SizeKb
+		total.SizeKbActual += usage.SizeKbActual
+		total.SizeKbUtilized += usage.SizeKbUtilized
+		total.NumObjects += usage.NumObjects
+	}
+	return total
 }
 
 type Bucket struct {
@@ -105,6 +198,99 @@ type Bucket struct {
 // Buckets represents the response of bucket requests
 type Buckets []Bucket
 
+// decodeBuckets decodes the three shapes of bucket responses: a list of names,
+// a list of stats or the stats of a single bucket. Every element becomes a Bucket
+// on its own, the name of stats elements is read from the stats.
+func decodeBuckets(r io.Reader) (Buckets, error) {
+	ret := Buckets{}
+	buffered := bufio.NewReader(r)
+
+	first, err := peekNonSpace(buffered)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(buffered)
+	switch first {
+	case '{':
+		stats := &Stats{}
+		if err = dec.Decode(stats); err != nil {
+			return nil, err
+		}
+		ret = append(ret, Bucket{Name: stats.Bucket, Stats: stats})
+	case '[':
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+		for dec.More() {
+			var element json.RawMessage
+
+			if err = dec.Decode(&element); err != nil {
+				return nil, err
+			}
+			bucket, errElement := decodeBucket(element)
+			if errElement != nil {
+				return nil, errElement
+			}
+			ret = append(ret, bucket)
+		}
+		if _, err = dec.Token(); err != nil {
+			return nil, err
+		}
+	case 'n':
+		var null interface{}
+		if err = dec.Decode(&null); err != nil {
+			return nil, err
+		}
+		if null != nil {
+			return nil, fmt.Errorf("unexpected bucket response")
+		}
+	default:
+		return nil, fmt.Errorf("unexpected bucket response starting with %q", first)
+	}
+	if _, err = dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after bucket response")
+	}
+	return ret, nil
+}
+
+// decodeBucket decodes an element of a bucket list, either a name or stats
+func decodeBucket(element json.RawMessage) (Bucket, error) {
+	if len(element) > 0 && element[0] == '"' {
+		var name string
+
+		if err := json.Unmarshal(element, &name); err != nil {
+			return Bucket{}, err
+		}
+		return Bucket{Name: name}, nil
+	}
+	if len(element) == 0 || element[0] != '{' {
+		return Bucket{}, fmt.Errorf("unexpected bucket element %s", element)
+	}
+	stats := &Stats{}
+	if err := json.Unmarshal(element, stats); err != nil {
+		return Bucket{}, err
+	}
+	return Bucket{Name: stats.Bucket, Stats: stats}, nil
+}
+
+// peekNonSpace returns the first non space byte of r without consuming it
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n':
+			continue
+		}
+		return b, r.UnreadByte()
+	}
+}
+
 // Policy represents the response of policy requests
 type Policy struct {
 	Acl struct {
@@ -152,8 +338,358 @@ type Quotas struct {
 	} `json:"user_quota"`
 }
 
+// RateLimit represents the response of rate limit requests
+type RateLimit struct {
+	MaxReadOps    int64 `json:"max_read_ops"`
+	MaxWriteOps   int64 `json:"max_write_ops"`
+	MaxReadBytes  int64 `json:"max_read_bytes"`
+	MaxWriteBytes int64 `json:"max_write_bytes"`
+	Enabled       bool  `json:"enabled"`
+}
+
+// GlobalRateLimit represents the response of global rate limit requests
+type GlobalRateLimit struct {
+	BucketRateLimit    RateLimit `json:"bucket_ratelimit"`
+	UserRateLimit      RateLimit `json:"user_ratelimit"`
+	AnonymousRateLimit RateLimit `json:"anonymous_ratelimit"`
+}
+
 // Capability represents the reponse of capability requests
 type Capability struct {
 	Perm string `json:"perm"`
 	Type string `json:"type"`
 }
+
+// LogInfo represents the response of log info requests
+type LogInfo struct {
+	NumObjects int    `json:"num_objects"`
+	Period     string `json:"period,omitempty"`
+	RealmEpoch int    `json:"realm_epoch,omitempty"`
+}
+
+// LogShardInfo represents the response of log shard info requests
+type LogShardInfo struct {
+	Marker     string `json:"marker"`
+	LastUpdate string `json:"last_update"`
+}
+
+// MetadataLogEntry represents an entry of the metadata log
+type MetadataLogEntry struct {
+	ID        string `json:"id"`
+	Section   string `json:"section"`
+	Name      string `json:"name"`
+	Timestamp string `json:"timestamp"`
+	Data      struct {
+		Status string `json:"status"`
+	} `json:"data"`
+}
+
+// MetadataLog represents the response of metadata log list requests
+type MetadataLog struct {
+	Marker    string             `json:"marker"`
+	Truncated bool               `json:"truncated"`
+	Entries   []MetadataLogEntry `json:"entries"`
+}
+
+// DataLogEntry represents an entry of the data log
+type DataLogEntry struct {
+	LogID        string `json:"log_id"`
+	LogTimestamp string `json:"log_timestamp"`
+	Entry        struct {
+		EntityType string `json:"entity_type"`
+		Key        string `json:"key"`
+		Timestamp  string `json:"timestamp"`
+	} `json:"entry"`
+}
+
+// DataLog represents the response of data log list requests
+type DataLog struct {
+	Marker    string         `json:"marker"`
+	Truncated bool           `json:"truncated"`
+	Entries   []DataLogEntry `json:"entries"`
+}
+
+// BucketIndexLogEntry represents an entry of the bucket index log
+type BucketIndexLogEntry struct {
+	OpID             string `json:"op_id"`
+	OpTag            string `json:"op_tag"`
+	Op               string `json:"op"`
+	Object           string `json:"object"`
+	Instance         string `json:"instance"`
+	State            string `json:"state"`
+	IndexVer         int64  `json:"index_ver"`
+	Timestamp        string `json:"timestamp"`
+	Owner            string `json:"owner"`
+	OwnerDisplayName string `json:"owner_display_name"`
+}
+
+// BucketIndexLogInfo represents the response of bucket index log info requests
+type BucketIndexLogInfo struct {
+	BucketVer   string `json:"bucket_ver"`
+	MasterVer   string `json:"master_ver"`
+	MaxMarker   string `json:"max_marker"`
+	SyncStopped bool   `json:"syncstopped"`
+	OldestGen   int    `json:"oldest_gen,omitempty"`
+	LatestGen   int    `json:"latest_gen,omitempty"`
+}
+
+// MetadataSyncStatus represents the response of metadata sync status requests
+type MetadataSyncStatus struct {
+	Info struct {
+		Status     string `json:"status"`
+		NumShards  int    `json:"num_shards"`
+		Period     string `json:"period"`
+		RealmEpoch int    `json:"realm_epoch"`
+	} `json:"info"`
+	Markers []struct {
+		Key int `json:"key"`
+		Val struct {
+			State          int    `json:"state"`
+			Marker         string `json:"marker"`
+			NextStepMarker string `json:"next_step_marker"`
+			TotalEntries   int    `json:"total_entries"`
+			Pos            int    `json:"pos"`
+			Timestamp      string `json:"timestamp"`
+			RealmEpoch     int    `json:"realm_epoch"`
+		} `json:"val"`
+	} `json:"markers"`
+}
+
+// DataSyncStatus represents the response of data sync status requests
+type DataSyncStatus struct {
+	Info struct {
+		Status     string `json:"status"`
+		NumShards  int    `json:"num_shards"`
+		InstanceID uint64 `json:"instance_id"`
+	} `json:"info"`
+	Markers []struct {
+		Key int `json:"key"`
+		Val struct {
+			Status         int    `json:"status"`
+			Marker         string `json:"marker"`
+			NextStepMarker string `json:"next_step_marker"`
+			TotalEntries   int    `json:"total_entries"`
+			Pos            int    `json:"pos"`
+			Timestamp      string `json:"timestamp"`
+		} `json:"val"`
+	} `json:"markers"`
+}
+
+// BucketIndexSyncStatus represents the response of bucket index sync status requests
+type BucketIndexSyncStatus []struct {
+	Key int `json:"key"`
+	Val struct {
+		Status     string `json:"status"`
+		FullMarker struct {
+			Position string `json:"position"`
+			Count    int    `json:"count"`
+		} `json:"full_marker"`
+		IncMarker struct {
+			Position  string `json:"position"`
+			Timestamp string `json:"timestamp"`
+		} `json:"inc_marker"`
+	} `json:"val"`
+}
+
+// Info represents the response of info requests
+type Info struct {
+	Info struct {
+		StorageBackends []struct {
+			Name      string `json:"name"`
+			ClusterID string `json:"cluster_id"`
+		} `json:"storage_backends"`
+	} `json:"info"`
+}
+
+// Tag represents a tag attached to an IAM entity
+type Tag struct {
+	Key   string `xml:"Key"`
+	Value string `xml:"Value"`
+}
+
+// Role represents the response of role requests
+type Role struct {
+	RoleID                   string `xml:"RoleId"`
+	RoleName                 string `xml:"RoleName"`
+	Path                     string `xml:"Path"`
+	Arn                      string `xml:"Arn"`
+	CreateDate               string `xml:"CreateDate"`
+	MaxSessionDuration       int    `xml:"MaxSessionDuration"`
+	AssumeRolePolicyDocument string `xml:"AssumeRolePolicyDocument"`
+	Description              string `xml:"Description"`
+	Tags                     []Tag  `xml:"Tags>member"`
+}
+
+// RolePolicy represents the response of role policy requests
+type RolePolicy struct {
+	PolicyName     string `xml:"PolicyName"`
+	RoleName       string `xml:"RoleName"`
+	PolicyDocument string `xml:"PolicyDocument"`
+}
+
+// OpenIDConnectProvider represents the response of OpenID Connect provider requests
+type OpenIDConnectProvider struct {
+	Arn            string   `xml:"-"`
+	URL            string   `xml:"Url"`
+	CreateDate     string   `xml:"CreateDate"`
+	ClientIDList   []string `xml:"ClientIDList>member"`
+	ThumbprintList []string `xml:"ThumbprintList>member"`
+}
+
+// Credentials represents temporary credentials returned by STS requests
+type Credentials struct {
+	AccessKeyID     string    `xml:"AccessKeyId"`
+	SecretAccessKey string    `xml:"SecretAccessKey"`
+	SessionToken    string    `xml:"SessionToken"`
+	Expiration      time.Time `xml:"Expiration"`
+}
+
+// AssumedRole represents the response of AssumeRole and AssumeRoleWithWebIdentity requests
+type AssumedRole struct {
+	Credentials     Credentials `xml:"Credentials"`
+	AssumedRoleUser struct {
+		Arn           string `xml:"Arn"`
+		AssumedRoleID string `xml:"AssumedRoleId"`
+	} `xml:"AssumedRoleUser"`
+	PackedPolicySize            int    `xml:"PackedPolicySize"`
+	SubjectFromWebIdentityToken string `xml:"SubjectFromWebIdentityToken"`
+	Audience                    string `xml:"Audience"`
+	Provider                    string `xml:"Provider"`
+}
+
+// TopicEndpoint represents the push endpoint of a topic
+type TopicEndpoint struct {
+	EndpointAddress string `xml:"EndpointAddress" json:"EndpointAddress"`
+	EndpointArgs    string `xml:"EndpointArgs" json:"EndpointArgs"`
+	EndpointTopic   string `xml:"EndpointTopic" json:"EndpointTopic"`
+	HasStoredSecret bool   `xml:"HasStoredSecret" json:"HasStoredSecret"`
+	Persistent      bool   `xml:"Persistent" json:"Persistent"`
+}
+
+// Topic represents the response of topic requests
+type Topic struct {
+	User       string        `xml:"User"`
+	Name       string        `xml:"Name"`
+	TopicArn   string        `xml:"TopicArn"`
+	OpaqueData string        `xml:"OpaqueData"`
+	EndPoint   TopicEndpoint `xml:"EndPoint"`
+	Policy     string        `xml:"Policy"`
+}
+
+// FilterRule represents a rule of a notification filter
+type FilterRule struct {
+	Name  string `xml:"Name"`
+	Value string `xml:"Value"`
+}
+
+// NotificationFilter represents the filter of a notification, rules of the same kind are ANDed
+type NotificationFilter struct {
+	Key      []FilterRule `xml:"S3Key>FilterRule,omitempty"`
+	Metadata []FilterRule `xml:"S3Metadata>FilterRule,omitempty"`
+	Tags     []FilterRule `xml:"S3Tags>FilterRule,omitempty"`
+}
+
+// MarshalXML leaves out the kinds without rules, the encoder would send them as empty elements
+func (f NotificationFilter) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type rules struct {
+		Rules []FilterRule `xml:"FilterRule"`
+	}
+
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	for _, kind := range []struct {
+		name  string
+		rules []FilterRule
+	}{{"S3Key", f.Key}, {"S3Metadata", f.Metadata}, {"S3Tags", f.Tags}} {
+		if len(kind.rules) == 0 {
+			continue
+		}
+		if err := e.EncodeElement(rules{kind.rules}, xml.StartElement{Name: xml.Name{Local: kind.name}}); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+// TopicConfiguration represents a notification sending the events of a bucket to a topic
+type TopicConfiguration struct {
+	ID     string              `xml:"Id"`
+	Topic  string              `xml:"Topic"`
+	Events []string            `xml:"Event"`
+	Filter *NotificationFilter `xml:"Filter,omitempty"`
+}
+
+// NotificationConfiguration represents the notifications of a bucket
+type NotificationConfiguration struct {
+	XMLName             xml.Name             `xml:"NotificationConfiguration"`
+	TopicConfigurations []TopicConfiguration `xml:"TopicConfiguration"`
+}
+
+// Event represents a record of the events pushed to a topic endpoint
+type Event struct {
+	EventVersion string `json:"eventVersion"`
+	EventSource  string `json:"eventSource"`
+	AwsRegion    string `json:"awsRegion"`
+	EventTime    string `json:"eventTime"`
+	EventName    string `json:"eventName"`
+	UserIdentity struct {
+		PrincipalID string `json:"principalId"`
+	} `json:"userIdentity"`
+	S3 struct {
+		ConfigurationID string `json:"configurationId"`
+		Bucket          struct {
+			Name string `json:"name"`
+			Arn  string `json:"arn"`
+			ID   string `json:"id"`
+		} `json:"bucket"`
+		Object struct {
+			Key       string `json:"key"`
+			Size      int64  `json:"size"`
+			ETag      string `json:"eTag"`
+			VersionID string `json:"versionId"`
+			Sequencer string `json:"sequencer"`
+		} `json:"object"`
+	} `json:"s3"`
+	OpaqueData string `json:"opaqueData"`
+}
+
+// QuotaInfo represents a quota as returned inside accounts, users and buckets
+type QuotaInfo struct {
+	Enabled    bool  `json:"enabled"`
+	CheckOnRaw bool  `json:"check_on_raw"`
+	MaxSize    int64 `json:"max_size"`
+	MaxSizeKb  int64 `json:"max_size_kb"`
+	MaxObjects int64 `json:"max_objects"`
+}
+
+// StorageStats represents the storage counters of a user or of a bucket usage category
+type StorageStats struct {
+	Size           int64 `json:"size"`
+	SizeActual     int64 `json:"size_actual"`
+	SizeUtilized   int64 `json:"size_utilized"`
+	SizeKb         int64 `json:"size_kb"`
+	SizeKbActual   int64 `json:"size_kb_actual"`
+	SizeKbUtilized int64 `json:"size_kb_utilized"`
+	NumObjects     int64 `json:"num_objects"`
+}
+
+// UserStats represents the storage statistics of a user
+type UserStats struct {
+	StorageStats
+}
+
+// Account represents the response of account requests
+type Account struct {
+	ID            string    `json:"id"`
+	Tenant        string    `json:"tenant"`
+	Name          string    `json:"name"`
+	Email         string    `json:"email"`
+	Quota         QuotaInfo `json:"quota"`
+	BucketQuota   QuotaInfo `json:"bucket_quota"`
+	MaxUsers      int       `json:"max_users"`
+	MaxRoles      int       `json:"max_roles"`
+	MaxGroups     int       `json:"max_groups"`
+	MaxBuckets    int       `json:"max_buckets"`
+	MaxAccessKeys int       `json:"max_access_keys"`
+}
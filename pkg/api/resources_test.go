@@ -0,0 +1,154 @@
+package radosAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestUserJSON(t *testing.T) {
+	Convey("Testing User decoding", t, func() {
+		user := User{}
+		err := json.Unmarshal([]byte(`{"user_id":"tenant$john","tenant":"tenant","suspended":1,`+
+			`"op_mask":"read, write, delete","default_placement":"default-placement",`+
+			`"placement_tags":["ssd"],"bucket_quota":{"enabled":true,"max_objects":10},`+
+			`"temp_url_keys":[{"key":0,"val":"secret"}],"type":"rgw","mfa_ids":[]}`), &user)
+		So(err, ShouldBeNil)
+		So(user.Suspended, ShouldBeTrue)
+		So(user.Tenant, ShouldEqual, "tenant")
+		So(user.OpMask, ShouldEqual, "read, write, delete")
+		So(user.PlacementTags, ShouldResemble, []string{"ssd"})
+		So(user.BucketQuota.Enabled, ShouldBeTrue)
+		So(user.TempURLKeys[0].Val, ShouldEqual, "secret")
+
+		err = json.Unmarshal([]byte(`{"suspended":false}`), &user)
+		So(err, ShouldBeNil)
+		So(user.Suspended, ShouldBeFalse)
+		err = json.Unmarshal([]byte(`{"suspended":"yes"}`), &user)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing User encoding", t, func() {
+		data, err := json.Marshal(User{UserID: "john", Suspended: true})
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, `"suspended":1`)
+	})
+}
+
+func TestStatsJSON(t *testing.T) {
+	Convey("Testing Stats decoding", t, func() {
+		stats := Stats{}
+		err := json.Unmarshal([]byte(`{"bucket":"photos","tenant":"","versioning":"enabled",`+
+			`"explicit_placement":{"data_pool":"","data_extra_pool":"","index_pool":""},`+
+			`"creation_time":"2024-05-02T10:00:00.000000Z","object_lock_enabled":true,"mfa_enabled":false,`+
+			`"usage":{"rgw.main":{"size":5368709120,"size_actual":5368709120,"size_utilized":5368709120,`+
+			`"size_kb":5242880,"size_kb_actual":5242880,"size_kb_utilized":5242880,"num_objects":2},`+
+			`"rgw.multimeta":{"size":0,"size_actual":0,"size_utilized":96,"size_kb":0,"size_kb_actual":0,`+
+			`"size_kb_utilized":1,"num_objects":3}},`+
+			`"bucket_quota":{"enabled":true,"check_on_raw":false,"max_size":-1,"max_size_kb":0,"max_objects":5000000000}}`), &stats)
+		So(err, ShouldBeNil)
+		So(stats.Versioning, ShouldEqual, "enabled")
+		So(stats.ObjectLockEnabled, ShouldBeTrue)
+		So(stats.Usage, ShouldContainKey, UsageMain)
+		So(stats.Usage[UsageMain].Size, ShouldEqual, int64(5368709120))
+		So(stats.Usage[UsageMultimeta].NumObjects, ShouldEqual, int64(3))
+		total := stats.TotalUsage()
+		So(total.NumObjects, ShouldEqual, int64(5))
+		So(total.SizeUtilized, ShouldEqual, int64(5368709216))
+		So(stats.BucketQuota.Enabled, ShouldBeTrue)
+		So(stats.BucketQuota.MaxSize, ShouldEqual, int64(-1))
+		So(stats.BucketQuota.MaxObjects, ShouldEqual, int64(5000000000))
+	})
+}
+
+func TestDecodeBuckets(t *testing.T) {
+	Convey("Testing decodeBuckets with a list of names", t, func() {
+		buckets, err := decodeBuckets(strings.NewReader(` ["photos", "backups"]`))
+		So(err, ShouldBeNil)
+		So(buckets, ShouldResemble, Buckets{{Name: "photos"}, {Name: "backups"}})
+	})
+
+	Convey("Testing decodeBuckets with a list of stats", t, func() {
+		buckets, err := decodeBuckets(strings.NewReader(`[{"bucket":"photos","num_shards":11},{"bucket":"backups"}]`))
+		So(err, ShouldBeNil)
+		So(len(buckets), ShouldEqual, 2)
+		So(buckets[0].Name, ShouldEqual, "photos")
+		So(buckets[0].Stats.NumShards, ShouldEqual, 11)
+		So(buckets[1].Name, ShouldEqual, "backups")
+	})
+
+	Convey("Testing decodeBuckets with stats without names", t, func() {
+		buckets, err := decodeBuckets(strings.NewReader(`["photos",{"bucket":"backups"},{"bucket":"logs"}]`))
+		So(err, ShouldBeNil)
+		So(len(buckets), ShouldEqual, 3)
+		So(buckets[0].Stats, ShouldBeNil)
+		So(buckets[1].Name, ShouldEqual, "backups")
+		So(buckets[2].Name, ShouldEqual, "logs")
+	})
+
+	Convey("Testing decodeBuckets with a single bucket", t, func() {
+		buckets, err := decodeBuckets(strings.NewReader(`{"bucket":"photos","owner":"john"}`))
+		So(err, ShouldBeNil)
+		So(len(buckets), ShouldEqual, 1)
+		So(buckets[0].Stats.Owner, ShouldEqual, "john")
+	})
+
+	Convey("Testing decodeBuckets with invalid responses", t, func() {
+		for _, body := range []string{``, `42`, `[42]`, `["photos"`, `[] []`, `{"bucket":1}`} {
+			_, err := decodeBuckets(strings.NewReader(body))
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Testing GetBucket decodes the response body", t, func() {
+		api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("uid") == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"Code":"NoSuchUser"}`)
+				return
+			}
+			fmt.Fprint(w, `["photos",{"bucket":"backups","owner":"john"}]`)
+		})
+		defer server.Close()
+
+		buckets, err := api.GetBucket(BucketConfig{UID: "john"})
+		So(err, ShouldBeNil)
+		So(len(buckets), ShouldEqual, 2)
+		So(buckets[1].Stats.Owner, ShouldEqual, "john")
+
+		buckets, err = api.GetBucket(BucketConfig{UID: "missing"})
+		So(buckets, ShouldBeNil)
+		So(err.Error(), ShouldEqual, "[404]: NoSuchUser")
+	})
+}
+
+func FuzzDecodeBuckets(f *testing.F) {
+	for _, seed := range []string{
+		`["photos","backups"]`,
+		`[{"bucket":"photos","usage":{"rgw.main":{"num_objects":1}}}]`,
+		`{"bucket":"photos"}`,
+		`["photos",{"bucket":"backups"}]`,
+		`null`,
+	} {
+		f.Add([]byte(seed))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		buckets, err := decodeBuckets(strings.NewReader(string(data)))
+		if err != nil {
+			return
+		}
+		for _, bucket := range buckets {
+			if bucket.Stats != nil && bucket.Name != bucket.Stats.Bucket {
+				t.Fatalf("bucket %q paired with stats of %q", bucket.Name, bucket.Stats.Bucket)
+			}
+		}
+		var variant interface{}
+		if errStd := json.Unmarshal(data, &variant); errStd != nil {
+			t.Fatalf("decoded invalid JSON %q", data)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package radosAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+// RetentionConfig usage retention request
+type RetentionConfig struct {
+	UID     string        // The user whose usage is trimmed. If not specified will apply to all users
+	Keep    time.Duration // The usage more recent than Keep is kept
+	Archive string        // The file the trimmed usage is written to before its deletion, it must not exist
+	Confirm bool          // Required when uid is not specified, in order to acknowledge multi user data removal
+}
+
+// UsageArchive represents the usage written by TrimUsage before its deletion
+type UsageArchive struct {
+	UID       string    `json:"uid,omitempty"`
+	End       time.Time `json:"end"` // The usage before End was trimmed
+	CreatedAt time.Time `json:"created_at"`
+	Usage     *Usage    `json:"usage"`
+}
+
+// TrimUsage removes the usage older than Keep, of a user or of all users.
+// The usage is written to Archive first and isn't removed if it can't be written.
+// Nothing is written nor removed if there is no usage older than Keep.
+//
+// !! caps: usage=read, usage=write !!
+//
+// @UID
+// @Keep
+// @Archive
+// @Confirm
+func (api *API) TrimUsage(conf RetentionConfig) (*Usage, error) {
+	if conf.Keep <= 0 {
+		return nil, errors.New("Keep field should be positive")
+	}
+	if conf.Archive == "" {
+		return nil, errors.New("Archive field is required")
+	}
+	if conf.UID == "" && !conf.Confirm {
+		return nil, errors.New("Confirm field is required to trim the usage of all users")
+	}
+	// the gateway ignores the sub second part of the dates
+	end := time.Now().Add(-conf.Keep).UTC().Truncate(time.Second)
+
+	usage, err := api.GetUsage(UsageConfig{UID: conf.UID, End: &end, ShowEntries: true, ShowSummary: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(usage.Entries) == 0 && len(usage.Summary) == 0 {
+		return usage, nil
+	}
+	if err = writeUsageArchive(conf.Archive, &UsageArchive{
+		UID:       conf.UID,
+		End:       end,
+		CreatedAt: time.Now().UTC(),
+		Usage:     usage,
+	}); err != nil {
+		return nil, err
+	}
+	if err = api.DeleteUsage(UsageConfig{UID: conf.UID, End: &end, RemoveAll: conf.UID == ""}); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// writeUsageArchive writes archive to path and syncs it, an existing file is never overwritten
+func writeUsageArchive(path string, archive *UsageArchive) error {
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(append(data, '\n')); err == nil {
+		err = file.Sync()
+	}
+	if errClose := file.Close(); err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package radosAPI
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTrimUsage(t *testing.T) {
+	var (
+		requests []string
+		archived bool
+		usage    string
+	)
+
+	dir, err := ioutil.TempDir("", "retention")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "usage.json")
+
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RawQuery)
+		if r.Method == "DELETE" {
+			_, err := os.Stat(path)
+			archived = err == nil
+			return
+		}
+		fmt.Fprint(w, usage)
+	})
+	defer server.Close()
+
+	Convey("Testing TrimUsage", t, func() {
+		requests = nil
+		usage = `{"entries":[{"owner":"john","buckets":[{"bucket":"photos","time":"2020-01-01 00:00:00"}]}],"summary":[]}`
+		os.Remove(path)
+		_, err := api.TrimUsage(RetentionConfig{Archive: path})
+		So(err, ShouldNotBeNil)
+		_, err = api.TrimUsage(RetentionConfig{Keep: time.Hour})
+		So(err, ShouldNotBeNil)
+		_, err = api.TrimUsage(RetentionConfig{Keep: time.Hour, Archive: path})
+		So(err, ShouldNotBeNil)
+		So(requests, ShouldBeEmpty)
+
+		end := time.Now().Add(-24 * time.Hour).UTC()
+		trimmed, err := api.TrimUsage(RetentionConfig{UID: "john", Keep: 24 * time.Hour, Archive: path})
+		So(err, ShouldBeNil)
+		So(trimmed.Entries[0].Owner, ShouldEqual, "john")
+		So(archived, ShouldBeTrue)
+		So(requests, ShouldHaveLength, 2)
+		So(requests[1], ShouldContainSubstring, "uid=john")
+		So(requests[1], ShouldNotContainSubstring, "remove-all")
+		So(requests[1], ShouldContainSubstring, "end="+end.Format("2006-01-02"))
+
+		data, err := ioutil.ReadFile(path)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, `"photos"`)
+
+		Convey("An existing archive is never overwritten", func() {
+			requests = nil
+			_, err := api.TrimUsage(RetentionConfig{UID: "john", Keep: time.Hour, Archive: path})
+			So(err, ShouldNotBeNil)
+			So(requests, ShouldHaveLength, 1)
+		})
+
+		Convey("The usage of all users requires a confirmation", func() {
+			requests = nil
+			os.Remove(path)
+			_, err := api.TrimUsage(RetentionConfig{Keep: time.Hour, Archive: path, Confirm: true})
+			So(err, ShouldBeNil)
+			So(requests[1], ShouldContainSubstring, "remove-all=True")
+			So(requests[1], ShouldNotContainSubstring, "uid=")
+		})
+
+		Convey("Nothing is written without usage to trim", func() {
+			requests = nil
+			usage = `{"entries":[],"summary":[]}`
+			other := filepath.Join(dir, "empty.json")
+			_, err := api.TrimUsage(RetentionConfig{UID: "john", Keep: time.Hour, Archive: other})
+			So(err, ShouldBeNil)
+			So(requests, ShouldHaveLength, 1)
+			_, err = os.Stat(other)
+			So(os.IsNotExist(err), ShouldBeTrue)
+		})
+	})
+}
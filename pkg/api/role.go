@@ -0,0 +1,310 @@
+package radosAPI
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/QuentinPerez/go-encodeUrl"
+)
+
+// RoleConfig role request
+type RoleConfig struct {
+	RoleName                 string   `url:"RoleName,ifStringIsNotEmpty"`                 // The name of the role
+	Path                     string   `url:"Path,ifStringIsNotEmpty"`                     // The path of the role, defaults to /
+	PathPrefix               string   `url:"PathPrefix,ifStringIsNotEmpty"`               // List the roles whose path starts with this prefix
+	AssumeRolePolicyDocument string   `url:"AssumeRolePolicyDocument,ifStringIsNotEmpty"` // The trust policy granting permission to assume the role
+	MaxSessionDuration       *int     `url:"MaxSessionDuration,itoaIfNotNil"`             // The maximum session duration in seconds, from 3600 to 43200
+	PolicyName               string   `url:"PolicyName,ifStringIsNotEmpty"`               // The name of the permission policy
+	PolicyDocument           string   `url:"PolicyDocument,ifStringIsNotEmpty"`           // The permission policy, or the trust policy for UpdateAssumeRolePolicy
+	Tags                     []Tag    `url:"-"`                                           // The tags to attach to the role
+	TagKeys                  []string `url:"-"`                                           // The keys of the tags to remove from the role
+}
+
+// iamCall sends an IAM action to the gateway and decodes the XML response in ret
+func (api *API) iamCall(action string, values url.Values, ret interface{}) error {
+	return api.iamSend(action, values, ret, true)
+}
+
+func (api *API) iamSend(action string, values url.Values, ret interface{}, sign bool) error {
+	values.Add("Action", action)
+	return api.doXML(request{operation: action, verb: "POST", route: "/", args: values, anonymous: !sign}, ret)
+}
+
+// doXML sends req and decodes the XML response in ret, XML errors are reported like the JSON ones
+func (api *API) doXML(req request, ret interface{}) error {
+	body, statusCode, err := api.do(req)
+	if err != nil {
+		if code := errorCode(body); code != "" {
+			return fmt.Errorf("[%v]: %v", statusCode, code)
+		}
+		return err
+	}
+	if ret == nil || len(body) == 0 {
+		return nil
+	}
+	return xml.Unmarshal(body, ret)
+}
+
+func (conf RoleConfig) values() (url.Values, error) {
+	values, errs := encurl.Translate(conf)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+	for idx, tag := range conf.Tags {
+		values.Add(fmt.Sprintf("Tags.member.%d.Key", idx+1), tag.Key)
+		values.Add(fmt.Sprintf("Tags.member.%d.Value", idx+1), tag.Value)
+	}
+	for idx, key := range conf.TagKeys {
+		values.Add(fmt.Sprintf("TagKeys.member.%d", idx+1), key)
+	}
+	return values, nil
+}
+
+// CreateRole creates a new role.
+//
+// !! caps: roles=write !!
+//
+// @RoleName
+// @Path
+// @AssumeRolePolicyDocument
+// @MaxSessionDuration
+// @Tags
+func (api *API) CreateRole(conf RoleConfig) (*Role, error) {
+	var ret struct {
+		Role Role `xml:"CreateRoleResult>Role"`
+	}
+
+	if conf.RoleName == "" {
+		return nil, errors.New("RoleName field is required")
+	}
+	if conf.AssumeRolePolicyDocument == "" {
+		return nil, errors.New("AssumeRolePolicyDocument field is required")
+	}
+	values, err := conf.values()
+	if err != nil {
+		return nil, err
+	}
+	if err = api.iamCall("CreateRole", values, &ret); err != nil {
+		return nil, err
+	}
+	return &ret.Role, nil
+}
+
+// GetRole gets role information.
+//
+// !! caps: roles=read !!
+//
+// @RoleName
+func (api *API) GetRole(conf RoleConfig) (*Role, error) {
+	var ret struct {
+		Role Role `xml:"GetRoleResult>Role"`
+	}
+
+	if conf.RoleName == "" {
+		return nil, errors.New("RoleName field is required")
+	}
+	if err := api.iamCall("GetRole", url.Values{"RoleName": {conf.RoleName}}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret.Role, nil
+}
+
+// ListRoles lists the roles, optionally filtered by path prefix.
+//
+// !! caps: roles=read !!
+//
+// @PathPrefix
+func (api *API) ListRoles(conf RoleConfig) ([]Role, error) {
+	var ret struct {
+		Roles []Role `xml:"ListRolesResult>Roles>member"`
+	}
+
+	values := url.Values{}
+	if conf.PathPrefix != "" {
+		values.Add("PathPrefix", conf.PathPrefix)
+	}
+	if err := api.iamCall("ListRoles", values, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Roles, nil
+}
+
+// DeleteRole removes an existing role. The role must not have any permission policy attached.
+//
+// !! caps: roles=write !!
+//
+// @RoleName
+func (api *API) DeleteRole(conf RoleConfig) error {
+	if conf.RoleName == "" {
+		return errors.New("RoleName field is required")
+	}
+	return api.iamCall("DeleteRole", url.Values{"RoleName": {conf.RoleName}}, nil)
+}
+
+// UpdateAssumeRolePolicy replaces the trust policy of a role with PolicyDocument.
+//
+// !! caps: roles=write !!
+//
+// @RoleName
+// @PolicyDocument
+func (api *API) UpdateAssumeRolePolicy(conf RoleConfig) error {
+	if conf.RoleName == "" {
+		return errors.New("RoleName field is required")
+	}
+	if conf.PolicyDocument == "" {
+		return errors.New("PolicyDocument field is required")
+	}
+	return api.iamCall("UpdateAssumeRolePolicy", url.Values{
+		"RoleName":       {conf.RoleName},
+		"PolicyDocument": {conf.PolicyDocument},
+	}, nil)
+}
+
+// PutRolePolicy adds or updates an inline permission policy of a role.
+//
+// !! caps: roles=write !!
+//
+// @RoleName
+// @PolicyName
+// @PolicyDocument
+func (api *API) PutRolePolicy(conf RoleConfig) error {
+	if conf.RoleName == "" {
+		return errors.New("RoleName field is required")
+	}
+	if conf.PolicyName == "" {
+		return errors.New("PolicyName field is required")
+	}
+	if conf.PolicyDocument == "" {
+		return errors.New("PolicyDocument field is required")
+	}
+	return api.iamCall("PutRolePolicy", url.Values{
+		"RoleName":       {conf.RoleName},
+		"PolicyName":     {conf.PolicyName},
+		"PolicyDocument": {conf.PolicyDocument},
+	}, nil)
+}
+
+// GetRolePolicy reads an inline permission policy of a role.
+//
+// !! caps: roles=read !!
+//
+// @RoleName
+// @PolicyName
+func (api *API) GetRolePolicy(conf RoleConfig) (*RolePolicy, error) {
+	var ret struct {
+		Policy RolePolicy `xml:"GetRolePolicyResult"`
+	}
+
+	if conf.RoleName == "" {
+		return nil, errors.New("RoleName field is required")
+	}
+	if conf.PolicyName == "" {
+		return nil, errors.New("PolicyName field is required")
+	}
+	if err := api.iamCall("GetRolePolicy", url.Values{
+		"RoleName":   {conf.RoleName},
+		"PolicyName": {conf.PolicyName},
+	}, &ret); err != nil {
+		return nil, err
+	}
+	return &ret.Policy, nil
+}
+
+// ListRolePolicies lists the names of the inline permission policies of a role.
+//
+// !! caps: roles=read !!
+//
+// @RoleName
+func (api *API) ListRolePolicies(conf RoleConfig) ([]string, error) {
+	var ret struct {
+		PolicyNames []string `xml:"ListRolePoliciesResult>PolicyNames>member"`
+	}
+
+	if conf.RoleName == "" {
+		return nil, errors.New("RoleName field is required")
+	}
+	if err := api.iamCall("ListRolePolicies", url.Values{"RoleName": {conf.RoleName}}, &ret); err != nil {
+		return nil, err
+	}
+	return ret.PolicyNames, nil
+}
+
+// DeleteRolePolicy removes an inline permission policy of a role.
+//
+// !! caps: roles=write !!
+//
+// @RoleName
+// @PolicyName
+func (api *API) DeleteRolePolicy(conf RoleConfig) error {
+	if conf.RoleName == "" {
+		return errors.New("RoleName field is required")
+	}
+	if conf.PolicyName == "" {
+		return errors.New("PolicyName field is required")
+	}
+	return api.iamCall("DeleteRolePolicy", url.Values{
+		"RoleName":   {conf.RoleName},
+		"PolicyName": {conf.PolicyName},
+	}, nil)
+}
+
+// TagRole adds or updates tags of a role.
+//
+// !! caps: roles=write !!
+//
+// @RoleName
+// @Tags
+func (api *API) TagRole(conf RoleConfig) error {
+	if conf.RoleName == "" {
+		return errors.New("RoleName field is required")
+	}
+	if len(conf.Tags) == 0 {
+		return errors.New("Tags field is required")
+	}
+	values, err := RoleConfig{RoleName: conf.RoleName, Tags: conf.Tags}.values()
+	if err != nil {
+		return err
+	}
+	return api.iamCall("TagRole", values, nil)
+}
+
+// ListRoleTags lists the tags of a role.
+//
+// !! caps: roles=read !!
+//
+// @RoleName
+func (api *API) ListRoleTags(conf RoleConfig) ([]Tag, error) {
+	var ret struct {
+		Tags []Tag `xml:"ListRoleTagsResult>Tags>member"`
+	}
+
+	if conf.RoleName == "" {
+		return nil, errors.New("RoleName field is required")
+	}
+	if err := api.iamCall("ListRoleTags", url.Values{"RoleName": {conf.RoleName}}, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Tags, nil
+}
+
+// UntagRole removes tags of a role.
+//
+// !! caps: roles=write !!
+//
+// @RoleName
+// @TagKeys
+func (api *API) UntagRole(conf RoleConfig) error {
+	if conf.RoleName == "" {
+		return errors.New("RoleName field is required")
+	}
+	if len(conf.TagKeys) == 0 {
+		return errors.New("TagKeys field is required")
+	}
+	values, err := RoleConfig{RoleName: conf.RoleName, TagKeys: conf.TagKeys}.values()
+	if err != nil {
+		return err
+	}
+	return api.iamCall("UntagRole", values, nil)
+}
@@ -0,0 +1,74 @@
+package radosAPI
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRole(t *testing.T) {
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch query.Get("Action") {
+		case "CreateRole":
+			fmt.Fprintf(w, `<CreateRoleResponse><CreateRoleResult><Role>`+
+				`<RoleName>%s</RoleName><Path>/</Path><Arn>arn:aws:iam:::role/%s</Arn>`+
+				`<MaxSessionDuration>3600</MaxSessionDuration></Role></CreateRoleResult></CreateRoleResponse>`,
+				query.Get("RoleName"), query.Get("RoleName"))
+		case "ListRoleTags":
+			fmt.Fprint(w, `<ListRoleTagsResponse><ListRoleTagsResult><Tags>`+
+				`<member><Key>team</Key><Value>storage</Value></member>`+
+				`</Tags></ListRoleTagsResult></ListRoleTagsResponse>`)
+		case "TagRole":
+			if query.Get("Tags.member.1.Key") != "team" || query.Get("Tags.member.1.Value") != "storage" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<ErrorResponse><Error><Code>NoSuchEntity</Code></Error></ErrorResponse>`)
+		}
+	})
+	defer server.Close()
+
+	Convey("Testing CreateRole without arguments", t, func() {
+		role, err := api.CreateRole(RoleConfig{})
+		So(role, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+		role, err = api.CreateRole(RoleConfig{RoleName: "S3Access"})
+		So(role, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing CreateRole", t, func() {
+		role, err := api.CreateRole(RoleConfig{
+			RoleName:                 "S3Access",
+			AssumeRolePolicyDocument: `{"Version":"2012-10-17"}`,
+		})
+		So(err, ShouldBeNil)
+		So(role.RoleName, ShouldEqual, "S3Access")
+		So(role.Arn, ShouldEqual, "arn:aws:iam:::role/S3Access")
+		So(role.MaxSessionDuration, ShouldEqual, 3600)
+	})
+
+	Convey("Testing role tags", t, func() {
+		err := api.TagRole(RoleConfig{RoleName: "S3Access"})
+		So(err, ShouldNotBeNil)
+		err = api.TagRole(RoleConfig{
+			RoleName: "S3Access",
+			Tags:     []Tag{{Key: "team", Value: "storage"}},
+		})
+		So(err, ShouldBeNil)
+		tags, err := api.ListRoleTags(RoleConfig{RoleName: "S3Access"})
+		So(err, ShouldBeNil)
+		So(tags, ShouldResemble, []Tag{{Key: "team", Value: "storage"}})
+	})
+
+	Convey("Testing GetRole on a missing role", t, func() {
+		role, err := api.GetRole(RoleConfig{RoleName: "Missing"})
+		So(role, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "[404]: NoSuchEntity")
+	})
+}
@@ -0,0 +1,154 @@
+package radosAPI
+
+import (
+	"fmt"
+	"path"
+)
+
+// allUsage is the confirmation of the removal of the usage of all users
+const allUsage = "*"
+
+// SafetyPolicy guards the operations removing data: RemoveUser, RemoveBucket, RemoveObject and DeleteUsage.
+// The purges are RemoveUser with PurgeData, RemoveBucket with PurgeObjects and DeleteUsage with RemoveAll.
+type SafetyPolicy struct {
+	ProtectedUIDs       []string // Patterns (see path.Match) of the users which can't be removed nor have their usage removed, the usage of all users can't be removed either
+	ProtectedBuckets    []string // Patterns of the buckets which can't be removed nor have objects removed, their owners can't be purged either
+	RequireConfirmation bool     // Purges must be confirmed with WithConfirmation, naming the purged user or bucket, or "*" for the usage of all users
+	MaxPurgeObjects     int64    // Purges of more objects, according to the bucket stats, are refused. 0 removes the limit
+	DryRun              *Plan    // If not nil, the guarded operations allowed by the policy are recorded in DryRun instead of being sent, see WithDryRun
+}
+
+// SetSafetyPolicy sets the policy guarding the operations removing data, there is none by default.
+// It should be called before api is used.
+func (api *API) SetSafetyPolicy(policy SafetyPolicy) error {
+	for _, pattern := range append(append([]string{}, policy.ProtectedUIDs...), policy.ProtectedBuckets...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	api.safety = &policy
+	return nil
+}
+
+// WithConfirmation returns a copy of api confirming the purges of token, see SafetyPolicy
+func (api *API) WithConfirmation(token string) *API {
+	clone := *api
+	clone.confirmation = token
+	return &clone
+}
+
+// matchAny returns the first pattern matching name
+func matchAny(patterns []string, name string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// confirm checks the purge of token is confirmed
+func (api *API) confirm(token string) error {
+	if api.safety.RequireConfirmation && api.confirmation != token {
+		return fmt.Errorf("purging %s requires WithConfirmation(%q)", token, token)
+	}
+	return nil
+}
+
+// checkPurgeSize checks the buckets purged hold at most MaxPurgeObjects objects
+func (api *API) checkPurgeSize(name string, stats []Stats) error {
+	var total int64
+
+	for _, stat := range stats {
+		if pattern, ok := matchAny(api.safety.ProtectedBuckets, stat.Bucket); ok {
+			return fmt.Errorf("purging %s would remove the bucket %s protected by %q", name, stat.Bucket, pattern)
+		}
+		total += stat.TotalUsage().NumObjects
+	}
+	if api.safety.MaxPurgeObjects > 0 && total > api.safety.MaxPurgeObjects {
+		return fmt.Errorf("purging %s would remove %d objects, more than %d", name, total, api.safety.MaxPurgeObjects)
+	}
+	return nil
+}
+
+// guarded returns the api sending the guarded operation, a copy recording it in dry run mode
+func (api *API) guarded() *API {
+	if api.safety.DryRun == nil {
+		return api
+	}
+	return api.WithDryRun(api.safety.DryRun)
+}
+
+// guardUser checks RemoveUser is allowed
+func (api *API) guardUser(conf UserConfig) (*API, error) {
+	if api.safety == nil {
+		return api, nil
+	}
+	uid := conf.UID
+	if conf.Tenant != "" {
+		uid = conf.Tenant + "$" + uid
+	}
+	if pattern, ok := matchAny(api.safety.ProtectedUIDs, uid); ok {
+		return nil, fmt.Errorf("%s is protected by %q", uid, pattern)
+	}
+	if conf.PurgeData {
+		if err := api.confirm(uid); err != nil {
+			return nil, err
+		}
+		if len(api.safety.ProtectedBuckets) > 0 || api.safety.MaxPurgeObjects > 0 {
+			stats, err := api.ListBucketStats(BucketConfig{UID: uid})
+			if err != nil {
+				return nil, err
+			}
+			if err = api.checkPurgeSize(uid, stats); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return api.guarded(), nil
+}
+
+// guardBucket checks RemoveBucket and RemoveObject are allowed
+func (api *API) guardBucket(conf BucketConfig) (*API, error) {
+	if api.safety == nil {
+		return api, nil
+	}
+	if pattern, ok := matchAny(api.safety.ProtectedBuckets, conf.Bucket); ok {
+		return nil, fmt.Errorf("%s is protected by %q", conf.Bucket, pattern)
+	}
+	if conf.PurgeObjects && conf.Object == "" {
+		if err := api.confirm(conf.Bucket); err != nil {
+			return nil, err
+		}
+		if api.safety.MaxPurgeObjects > 0 {
+			stats, err := api.ListBucketStats(BucketConfig{Bucket: conf.Bucket})
+			if err != nil {
+				return nil, err
+			}
+			if err = api.checkPurgeSize(conf.Bucket, stats); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return api.guarded(), nil
+}
+
+// guardUsage checks DeleteUsage is allowed
+func (api *API) guardUsage(conf UsageConfig) (*API, error) {
+	if api.safety == nil {
+		return api, nil
+	}
+	if conf.UID != "" {
+		if pattern, ok := matchAny(api.safety.ProtectedUIDs, conf.UID); ok {
+			return nil, fmt.Errorf("%s is protected by %q", conf.UID, pattern)
+		}
+		return api.guarded(), nil
+	}
+	if len(api.safety.ProtectedUIDs) > 0 {
+		return nil, fmt.Errorf("the usage of all users includes protected users")
+	}
+	if err := api.confirm(allUsage); err != nil {
+		return nil, err
+	}
+	return api.guarded(), nil
+}
@@ -0,0 +1,92 @@
+package radosAPI
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSafetyPolicy(t *testing.T) {
+	var deleted []string
+
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deleted = append(deleted, r.URL.Path+"?"+r.URL.RawQuery)
+			return
+		}
+		switch r.URL.Query().Get("bucket") {
+		case "small":
+			fmt.Fprint(w, `{"bucket":"small","usage":{"rgw.main":{"num_objects":10}}}`)
+		case "large":
+			fmt.Fprint(w, `{"bucket":"large","usage":{"rgw.main":{"num_objects":1000}}}`)
+		default:
+			fmt.Fprint(w, `[{"bucket":"small","usage":{"rgw.main":{"num_objects":10}}},`+
+				`{"bucket":"other","usage":{"rgw.main":{"num_objects":80}}}]`)
+		}
+	})
+	defer server.Close()
+
+	Convey("Testing the safety policy", t, func() {
+		deleted = nil
+		So(api.SetSafetyPolicy(SafetyPolicy{ProtectedUIDs: []string{"["}}), ShouldNotBeNil)
+		So(api.SetSafetyPolicy(SafetyPolicy{
+			ProtectedUIDs:       []string{"admin-*"},
+			ProtectedBuckets:    []string{"backup-*"},
+			RequireConfirmation: true,
+			MaxPurgeObjects:     100,
+		}), ShouldBeNil)
+
+		Convey("Protected users and buckets can't be removed", func() {
+			So(api.RemoveUser(UserConfig{UID: "admin-root"}), ShouldNotBeNil)
+			So(api.RemoveBucket(BucketConfig{Bucket: "backup-2020"}), ShouldNotBeNil)
+			So(api.RemoveObject(BucketConfig{Bucket: "backup-2020", Object: "dump"}), ShouldNotBeNil)
+			So(api.DeleteUsage(UsageConfig{UID: "admin-root"}), ShouldNotBeNil)
+			So(api.WithConfirmation(allUsage).DeleteUsage(UsageConfig{RemoveAll: true}), ShouldNotBeNil)
+			So(deleted, ShouldBeEmpty)
+
+			So(api.RemoveUser(UserConfig{UID: "john"}), ShouldBeNil)
+			So(api.RemoveObject(BucketConfig{Bucket: "photos", Object: "cat.png"}), ShouldBeNil)
+			So(deleted, ShouldHaveLength, 2)
+		})
+
+		Convey("Purges require a confirmation", func() {
+			So(api.RemoveUser(UserConfig{UID: "john", PurgeData: true}), ShouldNotBeNil)
+			So(api.WithConfirmation("jane").RemoveUser(UserConfig{UID: "john", PurgeData: true}), ShouldNotBeNil)
+			So(api.RemoveBucket(BucketConfig{Bucket: "small", PurgeObjects: true}), ShouldNotBeNil)
+			So(deleted, ShouldBeEmpty)
+
+			So(api.WithConfirmation("john").RemoveUser(UserConfig{UID: "john", PurgeData: true}), ShouldBeNil)
+			So(api.WithConfirmation("small").RemoveBucket(BucketConfig{Bucket: "small", PurgeObjects: true}), ShouldBeNil)
+			So(deleted, ShouldHaveLength, 2)
+		})
+
+		Convey("Purges of too many objects are refused", func() {
+			err := api.WithConfirmation("large").RemoveBucket(BucketConfig{Bucket: "large", PurgeObjects: true})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "1000 objects")
+			So(api.WithConfirmation("large").RemoveBucket(BucketConfig{Bucket: "large"}), ShouldBeNil)
+			So(deleted, ShouldHaveLength, 1)
+		})
+
+		Convey("The usage of all users requires a confirmation", func() {
+			So(api.SetSafetyPolicy(SafetyPolicy{RequireConfirmation: true}), ShouldBeNil)
+			So(api.DeleteUsage(UsageConfig{RemoveAll: true}), ShouldNotBeNil)
+			So(api.WithConfirmation(allUsage).DeleteUsage(UsageConfig{RemoveAll: true}), ShouldBeNil)
+			So(deleted, ShouldHaveLength, 1)
+		})
+
+		Convey("Dry run records the operations instead of sending them", func() {
+			plan := &Plan{}
+			So(api.SetSafetyPolicy(SafetyPolicy{MaxPurgeObjects: 100, DryRun: plan}), ShouldBeNil)
+			So(api.RemoveUser(UserConfig{UID: "john", PurgeData: true}), ShouldBeNil)
+			So(api.RemoveUser(UserConfig{UID: "admin-root"}), ShouldBeNil)
+			So(deleted, ShouldBeEmpty)
+			requests := plan.Requests()
+			So(len(requests), ShouldEqual, 2)
+			So(requests[0].String(), ShouldEqual, "DELETE /admin/user?format=json&purge-data=True&uid=john")
+			So(requests[1].Args.Get("uid"), ShouldEqual, "admin-root")
+		})
+	})
+}
@@ -0,0 +1,122 @@
+package radosAPI
+
+import (
+	"errors"
+	"time"
+
+	"github.com/QuentinPerez/go-encodeUrl"
+)
+
+// STSConfig STS request
+type STSConfig struct {
+	RoleArn          string `url:"RoleArn,ifStringIsNotEmpty"`          // The ARN of the role to assume
+	RoleSessionName  string `url:"RoleSessionName,ifStringIsNotEmpty"`  // An identifier for the assumed role session
+	DurationSeconds  *int   `url:"DurationSeconds,itoaIfNotNil"`        // The duration of the credentials, defaults to 3600
+	Policy           string `url:"Policy,ifStringIsNotEmpty"`           // A session policy further restricting the role permissions
+	ExternalID       string `url:"ExternalId,ifStringIsNotEmpty"`       // The external ID required by the trust policy
+	WebIdentityToken string `url:"WebIdentityToken,ifStringIsNotEmpty"` // The OpenID Connect token issued by the identity provider
+	ProviderID       string `url:"ProviderId,ifStringIsNotEmpty"`       // The identity provider of the token
+	SerialNumber     string `url:"SerialNumber,ifStringIsNotEmpty"`     // The serial number of the MFA device
+	TokenCode        string `url:"TokenCode,ifStringIsNotEmpty"`        // The code of the MFA device
+}
+
+// Expired returns true if the credentials can't be used anymore
+func (c Credentials) Expired() bool {
+	return !c.Expiration.IsZero() && time.Now().After(c.Expiration)
+}
+
+func (api *API) stsCall(action string, conf STSConfig, ret interface{}, sign bool) error {
+	values, errs := encurl.Translate(conf)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	values.Add("Version", "2011-06-15")
+	return api.iamSend(action, values, ret, sign)
+}
+
+// AssumeRole returns temporary credentials for a role the user is trusted by.
+// Use NewFromCredentials to make admin calls with the returned credentials.
+//
+// @RoleArn
+// @RoleSessionName
+// @DurationSeconds
+// @Policy
+// @ExternalID
+func (api *API) AssumeRole(conf STSConfig) (*AssumedRole, error) {
+	var ret struct {
+		AssumedRole AssumedRole `xml:"AssumeRoleResult"`
+	}
+
+	if conf.RoleArn == "" {
+		return nil, errors.New("RoleArn field is required")
+	}
+	if conf.RoleSessionName == "" {
+		return nil, errors.New("RoleSessionName field is required")
+	}
+	if err := api.stsCall("AssumeRole", STSConfig{
+		RoleArn:         conf.RoleArn,
+		RoleSessionName: conf.RoleSessionName,
+		DurationSeconds: conf.DurationSeconds,
+		Policy:          conf.Policy,
+		ExternalID:      conf.ExternalID,
+	}, &ret, true); err != nil {
+		return nil, err
+	}
+	return &ret.AssumedRole, nil
+}
+
+// AssumeRoleWithWebIdentity returns temporary credentials for a role trusting an OpenID Connect provider.
+// The request is authenticated by the token only, it is not signed with the keys of api.
+//
+// @RoleArn
+// @RoleSessionName
+// @WebIdentityToken
+// @DurationSeconds
+// @Policy
+// @ProviderID
+func (api *API) AssumeRoleWithWebIdentity(conf STSConfig) (*AssumedRole, error) {
+	var ret struct {
+		AssumedRole AssumedRole `xml:"AssumeRoleWithWebIdentityResult"`
+	}
+
+	if conf.RoleArn == "" {
+		return nil, errors.New("RoleArn field is required")
+	}
+	if conf.RoleSessionName == "" {
+		return nil, errors.New("RoleSessionName field is required")
+	}
+	if conf.WebIdentityToken == "" {
+		return nil, errors.New("WebIdentityToken field is required")
+	}
+	if err := api.stsCall("AssumeRoleWithWebIdentity", STSConfig{
+		RoleArn:          conf.RoleArn,
+		RoleSessionName:  conf.RoleSessionName,
+		WebIdentityToken: conf.WebIdentityToken,
+		DurationSeconds:  conf.DurationSeconds,
+		Policy:           conf.Policy,
+		ProviderID:       conf.ProviderID,
+	}, &ret, false); err != nil {
+		return nil, err
+	}
+	return &ret.AssumedRole, nil
+}
+
+// GetSessionToken returns temporary credentials carrying the permissions of the user.
+//
+// @DurationSeconds
+// @SerialNumber
+// @TokenCode
+func (api *API) GetSessionToken(conf STSConfig) (*Credentials, error) {
+	var ret struct {
+		Credentials Credentials `xml:"GetSessionTokenResult>Credentials"`
+	}
+
+	if err := api.stsCall("GetSessionToken", STSConfig{
+		DurationSeconds: conf.DurationSeconds,
+		SerialNumber:    conf.SerialNumber,
+		TokenCode:       conf.TokenCode,
+	}, &ret, true); err != nil {
+		return nil, err
+	}
+	return &ret.Credentials, nil
+}
@@ -0,0 +1,84 @@
+package radosAPI
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSTS(t *testing.T) {
+	expiration := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch query.Get("Action") {
+		case "AssumeRole", "AssumeRoleWithWebIdentity":
+			if query.Get("Action") == "AssumeRoleWithWebIdentity" && r.Header.Get("Authorization") != "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprintf(w, `<%sResponse><%sResult><Credentials>`+
+				`<AccessKeyId>TMPACCESS</AccessKeyId><SecretAccessKey>tmpsecret</SecretAccessKey>`+
+				`<SessionToken>token</SessionToken><Expiration>%s</Expiration></Credentials>`+
+				`<AssumedRoleUser><Arn>arn:aws:sts:::assumed-role/S3Access/session</Arn></AssumedRoleUser>`+
+				`</%sResult></%sResponse>`, query.Get("Action"), query.Get("Action"),
+				expiration.Format(time.RFC3339), query.Get("Action"), query.Get("Action"))
+		default:
+			if r.Header.Get("X-Amz-Security-Token") != "token" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"user_id":"UnitTest"}`)
+		}
+	})
+	defer server.Close()
+
+	Convey("Testing AssumeRole without arguments", t, func() {
+		role, err := api.AssumeRole(STSConfig{})
+		So(role, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing AssumeRole and NewFromCredentials", t, func() {
+		role, err := api.AssumeRole(STSConfig{
+			RoleArn:         "arn:aws:iam:::role/S3Access",
+			RoleSessionName: "session",
+		})
+		So(err, ShouldBeNil)
+		So(role.AssumedRoleUser.Arn, ShouldEqual, "arn:aws:sts:::assumed-role/S3Access/session")
+		So(role.Credentials.AccessKeyID, ShouldEqual, "TMPACCESS")
+		So(role.Credentials.Expiration.Equal(expiration), ShouldBeTrue)
+		So(role.Credentials.Expired(), ShouldBeFalse)
+
+		temporary, err := NewFromCredentials(server.URL, role.Credentials)
+		So(err, ShouldBeNil)
+		user, err := temporary.GetUser("UnitTest")
+		So(err, ShouldBeNil)
+		So(user.UserID, ShouldEqual, "UnitTest")
+	})
+
+	Convey("Testing AssumeRoleWithWebIdentity", t, func() {
+		role, err := api.AssumeRoleWithWebIdentity(STSConfig{
+			RoleArn:          "arn:aws:iam:::role/S3Access",
+			RoleSessionName:  "session",
+			WebIdentityToken: "jwt",
+		})
+		So(err, ShouldBeNil)
+		So(role.Credentials.SessionToken, ShouldEqual, "token")
+	})
+
+	Convey("Testing NewFromCredentials with expired credentials", t, func() {
+		temporary, err := NewFromCredentials(server.URL, Credentials{
+			AccessKeyID:     "TMPACCESS",
+			SecretAccessKey: "tmpsecret",
+			SessionToken:    "token",
+			Expiration:      time.Now().Add(-time.Minute),
+		})
+		So(err, ShouldBeNil)
+		user, err := temporary.GetUser("UnitTest")
+		So(user, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,391 @@
+package radosAPI
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/QuentinPerez/go-encodeUrl"
+)
+
+// LogConfig log request
+type LogConfig struct {
+	ID             *int   `url:"id,itoaIfNotNil"`                    // The shard ID
+	Marker         string `url:"marker,ifStringIsNotEmpty"`          // List the entries following this marker
+	MaxEntries     *int   `url:"max-entries,itoaIfNotNil"`           // The maximum number of entries to return
+	Period         string `url:"period,ifStringIsNotEmpty"`          // The period of the metadata log, defaults to the current period
+	Bucket         string `url:"bucket,ifStringIsNotEmpty"`          // The bucket of the bucket index log
+	BucketInstance string `url:"bucket-instance,ifStringIsNotEmpty"` // The bucket instance of the bucket index log, may contain the shard (bucket:instance:shard)
+	SourceZone     string `url:"source-zone,ifStringIsNotEmpty"`     // The zone the sync status is requested for
+}
+
+func (api *API) getLog(operation, logType string, conf LogConfig, ret interface{}, sub ...string) error {
+	var (
+		values = url.Values{}
+		errs   []error
+	)
+
+	values, errs = encurl.Translate(conf)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	values.Add("type", logType)
+	values.Add("format", "json")
+	body, _, err := api.call(operation, "GET", "/log", values, true, sub...)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, ret)
+}
+
+// GetMetadataLogInfo returns the number of shards of the metadata log.
+//
+// !! caps: mdlog=read !!
+//
+// @Period
+func (api *API) GetMetadataLogInfo(conf LogConfig) (*LogInfo, error) {
+	ret := &LogInfo{}
+
+	if err := api.getLog("GetMetadataLogInfo", "metadata", conf, ret, "info"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetMetadataLogShardInfo returns the last marker and update time of a metadata log shard.
+//
+// !! caps: mdlog=read !!
+//
+// @ID
+// @Period
+func (api *API) GetMetadataLogShardInfo(conf LogConfig) (*LogShardInfo, error) {
+	ret := &LogShardInfo{}
+
+	if conf.ID == nil {
+		return nil, errors.New("ID field is required")
+	}
+	if err := api.getLog("GetMetadataLogShardInfo", "metadata", conf, ret, "info"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ListMetadataLog lists the entries of a metadata log shard following Marker.
+//
+// !! caps: mdlog=read !!
+//
+// @ID
+// @Marker
+// @MaxEntries
+// @Period
+func (api *API) ListMetadataLog(conf LogConfig) (*MetadataLog, error) {
+	ret := &MetadataLog{}
+
+	if conf.ID == nil {
+		return nil, errors.New("ID field is required")
+	}
+	if err := api.getLog("ListMetadataLog", "metadata", conf, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetMetadataSyncStatus returns the metadata sync status of the zone, one marker per shard.
+//
+// !! caps: mdlog=read !!
+func (api *API) GetMetadataSyncStatus() (*MetadataSyncStatus, error) {
+	ret := &MetadataSyncStatus{}
+
+	if err := api.getLog("GetMetadataSyncStatus", "metadata", LogConfig{}, ret, "status"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetDataLogInfo returns the number of shards of the data log.
+//
+// !! caps: datalog=read !!
+func (api *API) GetDataLogInfo() (*LogInfo, error) {
+	ret := &LogInfo{}
+
+	if err := api.getLog("GetDataLogInfo", "data", LogConfig{}, ret, "info"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetDataLogShardInfo returns the last marker and update time of a data log shard.
+//
+// !! caps: datalog=read !!
+//
+// @ID
+func (api *API) GetDataLogShardInfo(conf LogConfig) (*LogShardInfo, error) {
+	ret := &LogShardInfo{}
+
+	if conf.ID == nil {
+		return nil, errors.New("ID field is required")
+	}
+	if err := api.getLog("GetDataLogShardInfo", "data", conf, ret, "info"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ListDataLog lists the entries of a data log shard following Marker.
+//
+// !! caps: datalog=read !!
+//
+// @ID
+// @Marker
+// @MaxEntries
+func (api *API) ListDataLog(conf LogConfig) (*DataLog, error) {
+	ret := &DataLog{}
+
+	if conf.ID == nil {
+		return nil, errors.New("ID field is required")
+	}
+	if err := api.getLog("ListDataLog", "data", conf, ret, "extra-info=true"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetDataSyncStatus returns the data sync status of the zone against SourceZone, one marker per shard.
+//
+// !! caps: datalog=read !!
+//
+// @SourceZone
+func (api *API) GetDataSyncStatus(conf LogConfig) (*DataSyncStatus, error) {
+	ret := &DataSyncStatus{}
+
+	if conf.SourceZone == "" {
+		return nil, errors.New("SourceZone field is required")
+	}
+	if err := api.getLog("GetDataSyncStatus", "data", conf, ret, "status"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetBucketIndexLogInfo returns the versions and last marker of a bucket index log.
+//
+// !! caps: bilog=read !!
+//
+// @Bucket
+// @BucketInstance
+func (api *API) GetBucketIndexLogInfo(conf LogConfig) (*BucketIndexLogInfo, error) {
+	ret := &BucketIndexLogInfo{}
+
+	if conf.Bucket == "" && conf.BucketInstance == "" {
+		return nil, errors.New("Bucket or BucketInstance field is required")
+	}
+	if err := api.getLog("GetBucketIndexLogInfo", "bucket-index", conf, ret, "info"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// ListBucketIndexLog lists the entries of a bucket index log following Marker.
+//
+// !! caps: bilog=read !!
+//
+// @Bucket
+// @BucketInstance
+// @Marker
+// @MaxEntries
+func (api *API) ListBucketIndexLog(conf LogConfig) ([]BucketIndexLogEntry, error) {
+	var ret []BucketIndexLogEntry
+
+	if conf.Bucket == "" && conf.BucketInstance == "" {
+		return nil, errors.New("Bucket or BucketInstance field is required")
+	}
+	if err := api.getLog("ListBucketIndexLog", "bucket-index", conf, &ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// GetBucketIndexSyncStatus returns the per shard sync status of a bucket against SourceZone.
+//
+// !! caps: bilog=read !!
+//
+// @Bucket
+// @SourceZone
+func (api *API) GetBucketIndexSyncStatus(conf LogConfig) (BucketIndexSyncStatus, error) {
+	var ret BucketIndexSyncStatus
+
+	if conf.Bucket == "" && conf.BucketInstance == "" {
+		return nil, errors.New("Bucket or BucketInstance field is required")
+	}
+	if conf.SourceZone == "" {
+		return nil, errors.New("SourceZone field is required")
+	}
+	if err := api.getLog("GetBucketIndexSyncStatus", "bucket-index", conf, &ret, "status"); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// SyncStatusConfig sync status summary request
+type SyncStatusConfig struct {
+	Type       string // The log to compare, options are: metadata (default), data
+	SourceZone string // The zone the local gateway syncs from, required for data
+}
+
+// ShardSyncStatus represents the replication state of a single log shard
+type ShardSyncStatus struct {
+	Shard          int           // The shard ID
+	FullSync       bool          // The shard is still in full sync, incremental markers are meaningless
+	SourceMarker   string        // The last marker of the shard on the source zone
+	SyncMarker     string        // The last marker applied by the local zone
+	LastUpdate     time.Time     // The last update of the shard on the source zone
+	OldestUnsynced time.Time     // The timestamp of the oldest entry not yet applied, zero when caught up
+	Lag            time.Duration // The time the oldest entry not yet applied has been waiting
+}
+
+// CaughtUp returns true if every entry of the source shard has been applied
+func (s ShardSyncStatus) CaughtUp() bool {
+	return !s.FullSync && s.OldestUnsynced.IsZero()
+}
+
+// SyncStatus represents the replication state of a log across all of its shards
+type SyncStatus struct {
+	Type           string            // metadata or data
+	Status         string            // The sync state reported by the local zone (init, building-full-sync-maps, sync)
+	Shards         []ShardSyncStatus // The state of each shard
+	Behind         int               // The number of shards which are not caught up
+	OldestUnsynced time.Time         // The oldest entry not yet applied across all shards
+	MaxLag         time.Duration     // The largest lag across all shards
+}
+
+// SyncStatus summarizes the replication lag of the zone served by api against the zone served by source.
+// The local sync markers are compared to the source log shards, and for each shard which is behind
+// the oldest entry not yet applied is fetched from the source log.
+//
+// !! caps: mdlog=read, datalog=read (on both zones) !!
+//
+// @Type
+// @SourceZone
+func (api *API) SyncStatus(source *API, conf SyncStatusConfig) (*SyncStatus, error) {
+	if source == nil {
+		return nil, errors.New("source API is required")
+	}
+
+	var (
+		ret     = &SyncStatus{Type: conf.Type}
+		markers = map[int]string{}
+		full    = map[int]bool{}
+		period  string
+	)
+
+	switch conf.Type {
+	case "", "metadata":
+		ret.Type = "metadata"
+		status, err := api.GetMetadataSyncStatus()
+		if err != nil {
+			return nil, err
+		}
+		ret.Status = status.Info.Status
+		period = status.Info.Period
+		for _, m := range status.Markers {
+			markers[m.Key] = m.Val.Marker
+			full[m.Key] = m.Val.State != 1
+		}
+	case "data":
+		if conf.SourceZone == "" {
+			return nil, errors.New("SourceZone field is required")
+		}
+		status, err := api.GetDataSyncStatus(LogConfig{SourceZone: conf.SourceZone})
+		if err != nil {
+			return nil, err
+		}
+		ret.Status = status.Info.Status
+		for _, m := range status.Markers {
+			markers[m.Key] = m.Val.Marker
+			full[m.Key] = m.Val.Status != 1
+		}
+	default:
+		return nil, errors.New("Type field should be metadata or data")
+	}
+
+	now := time.Now()
+	for shard := 0; shard < len(markers); shard++ {
+		var (
+			id        = shard
+			info      *LogShardInfo
+			oldest    string
+			err       error
+			shardConf = LogConfig{ID: &id, Period: period}
+			one       = 1
+		)
+
+		sync, ok := markers[shard]
+		if !ok {
+			return nil, errors.New("missing sync marker for shard")
+		}
+		if ret.Type == "metadata" {
+			info, err = source.GetMetadataLogShardInfo(shardConf)
+		} else {
+			info, err = source.GetDataLogShardInfo(shardConf)
+		}
+		if err != nil {
+			return nil, err
+		}
+		status := ShardSyncStatus{
+			Shard:        shard,
+			FullSync:     full[shard],
+			SourceMarker: info.Marker,
+			SyncMarker:   sync,
+		}
+		status.LastUpdate, _ = parseCephTime(info.LastUpdate)
+		if !status.FullSync && info.Marker != "" && info.Marker != sync {
+			shardConf.Marker = sync
+			shardConf.MaxEntries = &one
+			if ret.Type == "metadata" {
+				var log *MetadataLog
+				if log, err = source.ListMetadataLog(shardConf); err == nil && len(log.Entries) > 0 {
+					oldest = log.Entries[0].Timestamp
+				}
+			} else {
+				var log *DataLog
+				if log, err = source.ListDataLog(shardConf); err == nil && len(log.Entries) > 0 {
+					oldest = log.Entries[0].LogTimestamp
+				}
+			}
+			if err != nil {
+				return nil, err
+			}
+			if status.OldestUnsynced, err = parseCephTime(oldest); err != nil {
+				return nil, err
+			}
+			if !status.OldestUnsynced.IsZero() {
+				status.Lag = now.Sub(status.OldestUnsynced)
+			}
+		}
+		if !status.CaughtUp() {
+			ret.Behind++
+		}
+		if !status.OldestUnsynced.IsZero() && (ret.OldestUnsynced.IsZero() || status.OldestUnsynced.Before(ret.OldestUnsynced)) {
+			ret.OldestUnsynced = status.OldestUnsynced
+		}
+		if status.Lag > ret.MaxLag {
+			ret.MaxLag = status.Lag
+		}
+		ret.Shards = append(ret.Shards, status)
+	}
+	return ret, nil
+}
+
+// parseCephTime parses the timestamps returned by the logs, an empty string returns the zero time
+func parseCephTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	value = strings.Replace(value, " ", "T", 1)
+	if !strings.HasSuffix(value, "Z") {
+		value += "Z"
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
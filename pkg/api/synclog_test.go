@@ -0,0 +1,84 @@
+package radosAPI
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newTestAPI returns an API talking to an httptest server serving handler
+func newTestAPI(handler http.HandlerFunc) (*API, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	api, err := New(server.URL, "access", "secret")
+	if err != nil {
+		panic(err)
+	}
+	return api, server
+}
+
+func TestSyncStatus(t *testing.T) {
+	oldest := time.Now().Add(-10 * time.Minute).UTC()
+
+	local, localServer := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"info":{"status":"sync","num_shards":2,"period":"p1"},"markers":[`+
+			`{"key":0,"val":{"state":1,"marker":"1_00001"}},`+
+			`{"key":1,"val":{"state":1,"marker":"1_00005"}}]}`)
+	})
+	defer localServer.Close()
+
+	source, sourceServer := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("period") != "p1" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if _, ok := query["info"]; ok {
+			if query.Get("id") == "0" {
+				fmt.Fprint(w, `{"marker":"1_00003","last_update":"2019-04-01 12:00:00.000000Z"}`)
+			} else {
+				fmt.Fprint(w, `{"marker":"1_00005","last_update":"2019-04-01 11:00:00.000000Z"}`)
+			}
+			return
+		}
+		fmt.Fprintf(w, `{"marker":"1_00002","truncated":true,"entries":[{"section":"user","name":"JohnDoe","timestamp":"%s"}]}`,
+			oldest.Format("2006-01-02 15:04:05.000000Z"))
+	})
+	defer sourceServer.Close()
+
+	Convey("Testing SyncStatus without source", t, func() {
+		status, err := local.SyncStatus(nil, SyncStatusConfig{})
+		So(err, ShouldNotBeNil)
+		So(status, ShouldBeNil)
+	})
+
+	Convey("Testing SyncStatus data without SourceZone", t, func() {
+		status, err := local.SyncStatus(source, SyncStatusConfig{Type: "data"})
+		So(err, ShouldNotBeNil)
+		So(status, ShouldBeNil)
+	})
+
+	Convey("Testing SyncStatus metadata", t, func() {
+		status, err := local.SyncStatus(source, SyncStatusConfig{})
+		So(err, ShouldBeNil)
+		So(status, ShouldNotBeNil)
+		So(status.Type, ShouldEqual, "metadata")
+		So(status.Status, ShouldEqual, "sync")
+		So(len(status.Shards), ShouldEqual, 2)
+		So(status.Behind, ShouldEqual, 1)
+
+		So(status.Shards[0].CaughtUp(), ShouldBeFalse)
+		So(status.Shards[0].SourceMarker, ShouldEqual, "1_00003")
+		So(status.Shards[0].OldestUnsynced.Unix(), ShouldEqual, oldest.Unix())
+		So(status.Shards[0].Lag, ShouldBeGreaterThanOrEqualTo, 10*time.Minute)
+
+		So(status.Shards[1].CaughtUp(), ShouldBeTrue)
+		So(status.Shards[1].Lag, ShouldEqual, 0)
+
+		So(status.OldestUnsynced.Unix(), ShouldEqual, oldest.Unix())
+		So(status.MaxLag, ShouldEqual, status.Shards[0].Lag)
+	})
+}
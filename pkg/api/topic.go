@@ -0,0 +1,256 @@
+package radosAPI
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// TopicConfig topic request
+type TopicConfig struct {
+	Name          string            // The name of the topic
+	TopicArn      string            // The ARN of the topic, returned by CreateTopic
+	PushEndpoint  string            // The endpoint events are pushed to: http[s]://, amqp[s]:// or kafka://
+	OpaqueData    string            // Data added to every event sent to the topic
+	Persistent    *bool             // Push the events asynchronously, surviving gateway restarts
+	VerifySSL     *bool             // Verify the certificate of https and amqps endpoints
+	UseSSL        *bool             // Use SSL to connect to kafka endpoints
+	CALocation    string            // The CA used to verify kafka endpoints
+	AMQPExchange  string            // The exchange of amqp endpoints
+	AMQPAckLevel  string            // The ack level of amqp endpoints: none, broker (default), routable
+	KafkaAckLevel string            // The ack level of kafka endpoints: none, broker (default)
+	CloudEvents   *bool             // Add the CloudEvents headers to http endpoints
+	Attributes    map[string]string // Any other attribute of the topic
+}
+
+func (conf TopicConfig) attributes() map[string]string {
+	attributes := map[string]string{}
+
+	for k, v := range conf.Attributes {
+		attributes[k] = v
+	}
+	for k, v := range map[string]string{
+		"push-endpoint":   conf.PushEndpoint,
+		"OpaqueData":      conf.OpaqueData,
+		"ca-location":     conf.CALocation,
+		"amqp-exchange":   conf.AMQPExchange,
+		"amqp-ack-level":  conf.AMQPAckLevel,
+		"kafka-ack-level": conf.KafkaAckLevel,
+	} {
+		if v != "" {
+			attributes[k] = v
+		}
+	}
+	for k, v := range map[string]*bool{
+		"persistent":  conf.Persistent,
+		"verify-ssl":  conf.VerifySSL,
+		"use-ssl":     conf.UseSSL,
+		"cloudevents": conf.CloudEvents,
+	} {
+		if v != nil {
+			attributes[k] = strconv.FormatBool(*v)
+		}
+	}
+	return attributes
+}
+
+// CreateTopic creates a topic, or updates the attributes of an existing one, and returns its ARN.
+//
+// @Name
+// @PushEndpoint
+// @OpaqueData
+// @Persistent
+// @VerifySSL
+// @UseSSL
+// @CALocation
+// @AMQPExchange
+// @AMQPAckLevel
+// @KafkaAckLevel
+// @CloudEvents
+// @Attributes
+func (api *API) CreateTopic(conf TopicConfig) (string, error) {
+	var ret struct {
+		TopicArn string `xml:"CreateTopicResult>TopicArn"`
+	}
+
+	if conf.Name == "" {
+		return "", errors.New("Name field is required")
+	}
+	if conf.PushEndpoint != "" {
+		if _, err := url.Parse(conf.PushEndpoint); err != nil {
+			return "", err
+		}
+	}
+	values := url.Values{"Name": {conf.Name}}
+	attributes := conf.attributes()
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for idx, k := range keys {
+		values.Add(fmt.Sprintf("Attributes.entry.%d.key", idx+1), k)
+		values.Add(fmt.Sprintf("Attributes.entry.%d.value", idx+1), attributes[k])
+	}
+	if err := api.iamCall("CreateTopic", values, &ret); err != nil {
+		return "", err
+	}
+	return ret.TopicArn, nil
+}
+
+// GetTopic gets topic information.
+//
+// @TopicArn
+func (api *API) GetTopic(conf TopicConfig) (*Topic, error) {
+	var ret struct {
+		Entries []struct {
+			Key   string `xml:"key"`
+			Value string `xml:"value"`
+		} `xml:"GetTopicAttributesResult>Attributes>entry"`
+	}
+
+	if conf.TopicArn == "" {
+		return nil, errors.New("TopicArn field is required")
+	}
+	if err := api.iamCall("GetTopicAttributes", url.Values{"TopicArn": {conf.TopicArn}}, &ret); err != nil {
+		return nil, err
+	}
+	topic := &Topic{}
+	for _, entry := range ret.Entries {
+		switch entry.Key {
+		case "User":
+			topic.User = entry.Value
+		case "Name":
+			topic.Name = entry.Value
+		case "TopicArn":
+			topic.TopicArn = entry.Value
+		case "OpaqueData":
+			topic.OpaqueData = entry.Value
+		case "Policy":
+			topic.Policy = entry.Value
+		case "EndPoint":
+			if err := json.Unmarshal([]byte(entry.Value), &topic.EndPoint); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return topic, nil
+}
+
+// ListTopics lists the topics.
+func (api *API) ListTopics() ([]Topic, error) {
+	var ret struct {
+		Topics []Topic `xml:"ListTopicsResult>Topics>member"`
+	}
+
+	if err := api.iamCall("ListTopics", url.Values{}, &ret); err != nil {
+		return nil, err
+	}
+	return ret.Topics, nil
+}
+
+// DeleteTopic removes an existing topic, the notifications using it are not removed.
+//
+// @TopicArn
+func (api *API) DeleteTopic(conf TopicConfig) error {
+	if conf.TopicArn == "" {
+		return errors.New("TopicArn field is required")
+	}
+	return api.iamCall("DeleteTopic", url.Values{"TopicArn": {conf.TopicArn}}, nil)
+}
+
+// BucketNotificationConfig bucket notification request
+type BucketNotificationConfig struct {
+	Bucket        string                    // The bucket name
+	ID            string                    // The notification to remove, all of them if empty
+	Configuration NotificationConfiguration // The notifications of the bucket
+}
+
+// PutBucketNotification replaces the notifications of a bucket.
+// The request goes through the S3 API, api must hold the keys of the bucket owner.
+//
+// @Bucket
+// @Configuration
+func (api *API) PutBucketNotification(conf BucketNotificationConfig) error {
+	if conf.Bucket == "" {
+		return errors.New("Bucket field is required")
+	}
+	for _, notification := range conf.Configuration.TopicConfigurations {
+		if notification.ID == "" || notification.Topic == "" {
+			return errors.New("ID and Topic fields are required for every notification")
+		}
+	}
+	payload, err := xml.Marshal(conf.Configuration)
+	if err != nil {
+		return err
+	}
+	return api.doXML(request{
+		operation: "PutBucketNotification",
+		verb:      "PUT",
+		route:     "/" + conf.Bucket,
+		args:      url.Values{},
+		sub:       "notification",
+		payload:   payload,
+	}, nil)
+}
+
+// GetBucketNotification reads the notifications of a bucket.
+// The request goes through the S3 API, api must hold the keys of the bucket owner.
+//
+// @Bucket
+func (api *API) GetBucketNotification(conf BucketNotificationConfig) (*NotificationConfiguration, error) {
+	ret := &NotificationConfiguration{}
+
+	if conf.Bucket == "" {
+		return nil, errors.New("Bucket field is required")
+	}
+	if err := api.doXML(request{
+		operation: "GetBucketNotification",
+		verb:      "GET",
+		route:     "/" + conf.Bucket,
+		args:      url.Values{},
+		sub:       "notification",
+	}, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// DeleteBucketNotification removes a notification of a bucket, or all of them if ID is empty.
+// The request goes through the S3 API, api must hold the keys of the bucket owner.
+//
+// @Bucket
+// @ID
+func (api *API) DeleteBucketNotification(conf BucketNotificationConfig) error {
+	if conf.Bucket == "" {
+		return errors.New("Bucket field is required")
+	}
+	sub := "notification"
+	if conf.ID != "" {
+		sub = fmt.Sprintf("notification=%s", url.QueryEscape(conf.ID))
+	}
+	return api.doXML(request{
+		operation: "DeleteBucketNotification",
+		verb:      "DELETE",
+		route:     "/" + conf.Bucket,
+		args:      url.Values{},
+		sub:       sub,
+	}, nil)
+}
+
+// DecodeEvents decodes the events pushed by the gateway to an http endpoint
+func DecodeEvents(r io.Reader) ([]Event, error) {
+	var ret struct {
+		Records []Event `json:"Records"`
+	}
+
+	if err := json.NewDecoder(r).Decode(&ret); err != nil {
+		return nil, err
+	}
+	return ret.Records, nil
+}
@@ -0,0 +1,181 @@
+package radosAPI
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// pushedEvent is a record pushed by the gateway to an http endpoint, configured with the uploads id
+const pushedEvent = `{"Records":[{
+	"eventVersion":"2.2",
+	"eventSource":"ceph:s3",
+	"awsRegion":"default",
+	"eventTime":"2024-05-02T14:21:45.271912Z",
+	"eventName":"ObjectCreated:Put",
+	"userIdentity":{"principalId":"john"},
+	"requestParameters":{"sourceIPAddress":""},
+	"responseElements":{"x-amz-request-id":"b2a1e6b6-tx000001","x-amz-id-2":"1036-default-default"},
+	"s3":{
+		"s3SchemaVersion":"1.0",
+		"configurationId":"uploads",
+		"bucket":{"name":"unittestbucket","ownerIdentity":{"principalId":"john"},"arn":"arn:aws:s3:default::unittestbucket","id":"b2a1e6b6.4137.1"},
+		"object":{"key":"photo.jpg","size":42,"eTag":"9b2cf535f27731c974343645a3985328","versionId":"","sequencer":"B99D3366DBB8E914","metadata":[],"tags":[]}
+	},
+	"eventId":"1714659705.277163.9b2cf535f27731c974343645a3985328",
+	"opaqueData":"tenant-data"
+}]}`
+
+func TestTopic(t *testing.T) {
+	var (
+		topicQuery   url.Values
+		notification string
+		subresource  string
+	)
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path == "/unittestbucket" {
+			subresource = r.URL.RawQuery
+			switch r.Method {
+			case "PUT":
+				body, _ := ioutil.ReadAll(r.Body)
+				notification = string(body)
+			case "GET":
+				fmt.Fprint(w, notification)
+			case "DELETE":
+				w.WriteHeader(http.StatusNoContent)
+			}
+			return
+		}
+		switch query.Get("Action") {
+		case "CreateTopic":
+			topicQuery = query
+			fmt.Fprintf(w, `<CreateTopicResponse><CreateTopicResult><TopicArn>arn:aws:sns:default::%s</TopicArn>`+
+				`</CreateTopicResult></CreateTopicResponse>`, query.Get("Name"))
+		case "GetTopicAttributes":
+			fmt.Fprint(w, `<GetTopicAttributesResponse><GetTopicAttributesResult><Attributes>`+
+				`<entry><key>Name</key><value>unittest</value></entry>`+
+				`<entry><key>EndPoint</key><value>{"EndpointAddress":"http://hooks.example.com","Persistent":true}</value></entry>`+
+				`</Attributes></GetTopicAttributesResult></GetTopicAttributesResponse>`)
+		}
+	})
+	defer server.Close()
+
+	Convey("Testing CreateTopic without name", t, func() {
+		arn, err := api.CreateTopic(TopicConfig{})
+		So(arn, ShouldEqual, "")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing topic and bucket notification", t, func() {
+		persistent := true
+		arn, err := api.CreateTopic(TopicConfig{
+			Name:         "unittest",
+			PushEndpoint: "http://hooks.example.com",
+			OpaqueData:   "tenant-data",
+			Persistent:   &persistent,
+			Attributes:   map[string]string{"max_retries": "3"},
+		})
+		So(err, ShouldBeNil)
+		So(arn, ShouldEqual, "arn:aws:sns:default::unittest")
+		So(topicQuery.Get("Name"), ShouldEqual, "unittest")
+		attributes := map[string]string{}
+		for idx := 1; topicQuery.Get(fmt.Sprintf("Attributes.entry.%d.key", idx)) != ""; idx++ {
+			attributes[topicQuery.Get(fmt.Sprintf("Attributes.entry.%d.key", idx))] = topicQuery.Get(fmt.Sprintf("Attributes.entry.%d.value", idx))
+		}
+		So(attributes, ShouldResemble, map[string]string{
+			"push-endpoint": "http://hooks.example.com",
+			"OpaqueData":    "tenant-data",
+			"persistent":    "true",
+			"max_retries":   "3",
+		})
+
+		topic, err := api.GetTopic(TopicConfig{TopicArn: arn})
+		So(err, ShouldBeNil)
+		So(topic.Name, ShouldEqual, "unittest")
+		So(topic.EndPoint.EndpointAddress, ShouldEqual, "http://hooks.example.com")
+		So(topic.EndPoint.Persistent, ShouldBeTrue)
+
+		err = api.PutBucketNotification(BucketNotificationConfig{
+			Bucket: "unittestbucket",
+			Configuration: NotificationConfiguration{
+				TopicConfigurations: []TopicConfiguration{{
+					ID:     "uploads",
+					Topic:  arn,
+					Events: []string{"s3:ObjectCreated:*"},
+					Filter: &NotificationFilter{Key: []FilterRule{{Name: "suffix", Value: ".jpg"}}},
+				}},
+			},
+		})
+		So(err, ShouldBeNil)
+		So(subresource, ShouldEqual, "notification&")
+		So(notification, ShouldEqual, `<NotificationConfiguration><TopicConfiguration><Id>uploads</Id>`+
+			`<Topic>arn:aws:sns:default::unittest</Topic><Event>s3:ObjectCreated:*</Event>`+
+			`<Filter><S3Key><FilterRule><Name>suffix</Name><Value>.jpg</Value></FilterRule></S3Key></Filter>`+
+			`</TopicConfiguration></NotificationConfiguration>`)
+
+		notifications, err := api.GetBucketNotification(BucketNotificationConfig{Bucket: "unittestbucket"})
+		So(err, ShouldBeNil)
+		So(len(notifications.TopicConfigurations), ShouldEqual, 1)
+		So(notifications.TopicConfigurations[0].Filter.Key[0].Value, ShouldEqual, ".jpg")
+
+		err = api.DeleteBucketNotification(BucketNotificationConfig{Bucket: "unittestbucket", ID: "uploads"})
+		So(err, ShouldBeNil)
+		So(subresource, ShouldEqual, "notification=uploads&")
+	})
+
+	Convey("Testing the admin requests answered with 204 No Content", t, func() {
+		api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		})
+		defer server.Close()
+
+		So(api.RemoveUser(UserConfig{UID: "john"}), ShouldBeNil)
+		So(api.RemoveBucket(BucketConfig{Bucket: "unittestbucket"}), ShouldBeNil)
+	})
+}
+
+func TestDecodeEvents(t *testing.T) {
+	events := make(chan []Event, 1)
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		records, err := DecodeEvents(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		events <- records
+	}))
+	defer endpoint.Close()
+
+	Convey("Testing the events pushed to an http endpoint", t, func() {
+		resp, err := http.Post(endpoint.URL, "application/json", strings.NewReader(pushedEvent))
+		So(err, ShouldBeNil)
+		resp.Body.Close()
+		So(resp.StatusCode, ShouldEqual, http.StatusOK)
+
+		records := <-events
+		So(len(records), ShouldEqual, 1)
+		So(records[0].EventSource, ShouldEqual, "ceph:s3")
+		So(records[0].EventName, ShouldEqual, "ObjectCreated:Put")
+		So(records[0].EventTime, ShouldEqual, "2024-05-02T14:21:45.271912Z")
+		So(records[0].UserIdentity.PrincipalID, ShouldEqual, "john")
+		So(records[0].S3.ConfigurationID, ShouldEqual, "uploads")
+		So(records[0].S3.Bucket.Arn, ShouldEqual, "arn:aws:s3:default::unittestbucket")
+		So(records[0].S3.Object.Key, ShouldEqual, "photo.jpg")
+		So(records[0].S3.Object.Size, ShouldEqual, 42)
+		So(records[0].S3.Object.ETag, ShouldEqual, "9b2cf535f27731c974343645a3985328")
+		So(records[0].S3.Object.Sequencer, ShouldEqual, "B99D3366DBB8E914")
+		So(records[0].OpaqueData, ShouldEqual, "tenant-data")
+	})
+
+	Convey("Testing invalid events", t, func() {
+		_, err := DecodeEvents(strings.NewReader(`{"Records":{}}`))
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,68 @@
+package radosAPI
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation name of the spans
+const tracerName = "github.com/QuentinPerez/go-radosgw/pkg/api"
+
+// SetTracerProvider sets the provider of the spans recorded for every request,
+// the global provider is used by default.
+// It should be called before api is used.
+func (api *API) SetTracerProvider(provider trace.TracerProvider) {
+	api.tracer = provider
+}
+
+// startSpan starts the span of req, named after its operation
+func (api *API) startSpan(req request, route string) (context.Context, trace.Span) {
+	provider := api.tracer
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	attributes := []attribute.KeyValue{
+		attribute.String("http.request.method", req.verb),
+		attribute.String("radosgw.route", route),
+	}
+	if req.sub != "" {
+		attributes = append(attributes, attribute.String("radosgw.subresource", req.sub))
+	}
+	uid := req.args.Get("uid")
+	if uid == "" {
+		uid = req.args.Get("UserName")
+	}
+	if uid != "" {
+		attributes = append(attributes, attribute.String("radosgw.uid", uid))
+	}
+	bucket := req.args.Get("bucket")
+	if bucket == "" && !req.usePrefix && route != "/" {
+		bucket = strings.TrimPrefix(route, "/")
+	}
+	if bucket != "" {
+		attributes = append(attributes, attribute.String("radosgw.bucket", bucket))
+	}
+	return provider.Tracer(tracerName).Start(api.context(), "radosgw."+req.operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attributes...),
+	)
+}
+
+// endSpan records the outcome of a request and ends its span
+func endSpan(span trace.Span, statusCode int, code string, err error) {
+	if statusCode != 0 {
+		span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	}
+	if err != nil {
+		if code != "" {
+			span.SetAttributes(attribute.String("radosgw.error_code", code))
+		}
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
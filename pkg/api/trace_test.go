@@ -0,0 +1,89 @@
+package radosAPI
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	ret := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		ret[kv.Key] = kv.Value
+	}
+	return ret
+}
+
+func TestTracing(t *testing.T) {
+	var traceparent string
+
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		if r.URL.Query().Get("bucket") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"Code":"NoSuchBucket"}`)
+			return
+		}
+		fmt.Fprint(w, `{"user_id":"john","display_name":"John"}`)
+	})
+	defer server.Close()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	api.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+
+	Convey("Testing spans are named after the operation", t, func() {
+		exporter.Reset()
+		_, err := api.CreateUser(UserConfig{UID: "john", DisplayName: "John"})
+		So(err, ShouldBeNil)
+		spans := exporter.GetSpans()
+		So(len(spans), ShouldEqual, 1)
+		So(spans[0].Name, ShouldEqual, "radosgw.CreateUser")
+		attributes := spanAttributes(spans[0])
+		So(attributes["radosgw.route"].AsString(), ShouldEqual, "/admin/user")
+		So(attributes["radosgw.uid"].AsString(), ShouldEqual, "john")
+		So(attributes["http.response.status_code"].AsInt64(), ShouldEqual, 200)
+	})
+
+	Convey("Testing failed requests", t, func() {
+		exporter.Reset()
+		err := api.RemoveBucket(BucketConfig{Bucket: "missing"})
+		So(err, ShouldNotBeNil)
+		spans := exporter.GetSpans()
+		So(len(spans), ShouldEqual, 1)
+		So(spans[0].Name, ShouldEqual, "radosgw.RemoveBucket")
+		So(spans[0].Status.Code, ShouldEqual, codes.Error)
+		attributes := spanAttributes(spans[0])
+		So(attributes["radosgw.bucket"].AsString(), ShouldEqual, "missing")
+		So(attributes["radosgw.error_code"].AsString(), ShouldEqual, "NoSuchBucket")
+	})
+
+	Convey("Testing the context is propagated", t, func() {
+		exporter.Reset()
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "provisioning")
+		_, err := api.WithContext(ctx).GetUser("john")
+		parent.End()
+		So(err, ShouldBeNil)
+		spans := exporter.GetSpans()
+		So(len(spans), ShouldEqual, 2)
+		So(spans[0].Parent.SpanID(), ShouldEqual, parent.SpanContext().SpanID())
+		So(traceparent, ShouldContainSubstring, parent.SpanContext().TraceID().String())
+	})
+
+	Convey("Testing canceled contexts", t, func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := api.WithContext(ctx).GetUser("john")
+		So(err, ShouldNotBeNil)
+	})
+}
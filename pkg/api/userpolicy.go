@@ -0,0 +1,292 @@
+package radosAPI
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// StringList is a policy element which is either a single value or a list of values.
+// The numbers and booleans of the conditions are kept as strings, the way the gateway compares them.
+type StringList []string
+
+// UnmarshalJSON accepts both "value" and ["value", ...], with numbers and booleans as values
+func (l *StringList) UnmarshalJSON(data []byte) error {
+	var value interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+	list := make(StringList, 0, len(values))
+	for _, element := range values {
+		switch element := element.(type) {
+		case string:
+			list = append(list, element)
+		case json.Number:
+			list = append(list, element.String())
+		case bool:
+			list = append(list, strconv.FormatBool(element))
+		default:
+			return fmt.Errorf("invalid policy element %s", data)
+		}
+	}
+	*l = list
+	return nil
+}
+
+// MarshalJSON writes a single element list as a string
+func (l StringList) MarshalJSON() ([]byte, error) {
+	if len(l) == 1 {
+		return json.Marshal(l[0])
+	}
+	return json.Marshal([]string(l))
+}
+
+// Statement represents a statement of a policy document
+type Statement struct {
+	Sid         string                           `json:"Sid,omitempty"`
+	Effect      string                           `json:"Effect"`
+	Principal   json.RawMessage                  `json:"Principal,omitempty"`
+	Action      StringList                       `json:"Action,omitempty"`
+	NotAction   StringList                       `json:"NotAction,omitempty"`
+	Resource    StringList                       `json:"Resource,omitempty"`
+	NotResource StringList                       `json:"NotResource,omitempty"`
+	Condition   map[string]map[string]StringList `json:"Condition,omitempty"`
+}
+
+// PolicyDocument represents an IAM policy document
+type PolicyDocument struct {
+	Version   string      `json:"Version"`
+	ID        string      `json:"Id,omitempty"`
+	Statement []Statement `json:"Statement"`
+}
+
+// policyActions are the actions understood by the gateway policy engine
+var policyActions = map[string][]string{
+	"s3": {
+		"AbortMultipartUpload", "BypassGovernanceRetention", "CreateBucket", "DeleteBucket",
+		"DeleteBucketOwnershipControls", "DeleteBucketPolicy", "DeleteBucketPublicAccessBlock",
+		"DeleteBucketWebsite", "DeleteObject", "DeleteObjectTagging", "DeleteObjectVersion",
+		"DeleteObjectVersionTagging", "DeletePublicAccessBlock", "DeleteReplicationConfiguration",
+		"DescribeJob", "GetAccelerateConfiguration", "GetBucketAcl", "GetBucketCORS",
+		"GetBucketEncryption", "GetBucketLocation", "GetBucketLogging", "GetBucketNotification",
+		"GetBucketObjectLockConfiguration", "GetBucketOwnershipControls", "GetBucketPolicy",
+		"GetBucketPolicyStatus", "GetBucketPublicAccessBlock", "GetBucketRequestPayment",
+		"GetBucketTagging", "GetBucketVersioning", "GetBucketWebsite", "GetLifecycleConfiguration",
+		"GetObject", "GetObjectAcl", "GetObjectAttributes", "GetObjectLegalHold", "GetObjectRetention",
+		"GetObjectTagging", "GetObjectTorrent", "GetObjectVersion", "GetObjectVersionAcl",
+		"GetObjectVersionAttributes", "GetObjectVersionForReplication", "GetObjectVersionTagging",
+		"GetObjectVersionTorrent", "GetPublicAccessBlock", "GetReplicationConfiguration",
+		"ListAllMyBuckets", "ListBucket", "ListBucketMultipartUploads", "ListBucketVersions",
+		"ListMultipartUploadParts", "PutAccelerateConfiguration", "PutBucketAcl", "PutBucketCORS",
+		"PutBucketEncryption", "PutBucketLogging", "PutBucketNotification",
+		"PutBucketObjectLockConfiguration", "PutBucketOwnershipControls", "PutBucketPolicy",
+		"PutBucketPublicAccessBlock", "PutBucketRequestPayment", "PutBucketTagging",
+		"PutBucketVersioning", "PutBucketWebsite", "PutLifecycleConfiguration", "PutObject",
+		"PutObjectAcl", "PutObjectLegalHold", "PutObjectRetention", "PutObjectTagging",
+		"PutObjectVersionAcl", "PutObjectVersionTagging", "PutPublicAccessBlock",
+		"PutReplicationConfiguration", "ReplicateDelete", "ReplicateObject", "ReplicateTags",
+		"RestoreObject",
+	},
+	"iam": {
+		"CreateOpenIDConnectProvider", "CreateRole", "DeleteOpenIDConnectProvider", "DeleteRole",
+		"DeleteRolePolicy", "DeleteUserPolicy", "GetOpenIDConnectProvider", "GetRole",
+		"GetRolePolicy", "GetUserPolicy", "ListOpenIDConnectProviders", "ListRolePolicies",
+		"ListRoleTags", "ListRoles", "ListUserPolicies", "PutRolePolicy", "PutUserPolicy",
+		"TagRole", "UntagRole", "UpdateAssumeRolePolicy", "UpdateRole",
+	},
+	"sts": {
+		"AssumeRole", "AssumeRoleWithWebIdentity", "GetSessionToken", "TagSession",
+	},
+	"sns": {
+		"CreateTopic", "DeleteTopic", "GetTopicAttributes", "ListTopics", "Publish",
+		"SetTopicAttributes",
+	},
+}
+
+// validAction returns true if action, which may contain * and ? wildcards, matches a known action
+func validAction(action string) bool {
+	if action == "*" {
+		return true
+	}
+	tab := strings.SplitN(action, ":", 2)
+	if len(tab) != 2 {
+		return false
+	}
+	actions, ok := policyActions[strings.ToLower(tab[0])]
+	if !ok {
+		return false
+	}
+	pattern := strings.ToLower(tab[1])
+	for _, known := range actions {
+		if match, err := path.Match(pattern, strings.ToLower(known)); err == nil && match {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the document is well formed and only references known actions
+func (doc PolicyDocument) Validate() error {
+	if doc.Version != "" && doc.Version != "2012-10-17" && doc.Version != "2008-10-17" {
+		return fmt.Errorf("invalid policy version %q", doc.Version)
+	}
+	if len(doc.Statement) == 0 {
+		return errors.New("Statement field is required")
+	}
+	for idx, statement := range doc.Statement {
+		if statement.Effect != "Allow" && statement.Effect != "Deny" {
+			return fmt.Errorf("statement %d: Effect should be Allow or Deny", idx)
+		}
+		if len(statement.Action) == 0 && len(statement.NotAction) == 0 {
+			return fmt.Errorf("statement %d: Action or NotAction field is required", idx)
+		}
+		if len(statement.Action) > 0 && len(statement.NotAction) > 0 {
+			return fmt.Errorf("statement %d: Action and NotAction are mutually exclusive", idx)
+		}
+		for _, action := range append(append([]string{}, statement.Action...), statement.NotAction...) {
+			if !validAction(action) {
+				return fmt.Errorf("statement %d: unknown action %q", idx, action)
+			}
+		}
+		if len(statement.Resource) > 0 && len(statement.NotResource) > 0 {
+			return fmt.Errorf("statement %d: Resource and NotResource are mutually exclusive", idx)
+		}
+	}
+	return nil
+}
+
+// UserPolicy represents the response of user policy requests
+type UserPolicy struct {
+	UserName   string
+	PolicyName string
+	Policy     PolicyDocument
+}
+
+// UserPolicyConfig user policy request
+type UserPolicyConfig struct {
+	UID        string          // The user owning the policy
+	PolicyName string          // The name of the policy
+	Policy     *PolicyDocument // The policy document
+}
+
+// PutUserPolicy adds or updates an inline policy of a user.
+// The document is validated before being sent, resources are required for user policies.
+//
+// !! caps: user-policy=write !!
+//
+// @UID
+// @PolicyName
+// @Policy
+func (api *API) PutUserPolicy(conf UserPolicyConfig) error {
+	if conf.UID == "" {
+		return errors.New("UID field is required")
+	}
+	if conf.PolicyName == "" {
+		return errors.New("PolicyName field is required")
+	}
+	if conf.Policy == nil {
+		return errors.New("Policy field is required")
+	}
+	if err := conf.Policy.Validate(); err != nil {
+		return err
+	}
+	for idx, statement := range conf.Policy.Statement {
+		if len(statement.Resource) == 0 && len(statement.NotResource) == 0 {
+			return fmt.Errorf("statement %d: Resource or NotResource field is required", idx)
+		}
+	}
+	document, err := json.Marshal(conf.Policy)
+	if err != nil {
+		return err
+	}
+	return api.iamCall("PutUserPolicy", url.Values{
+		"UserName":       {conf.UID},
+		"PolicyName":     {conf.PolicyName},
+		"PolicyDocument": {string(document)},
+	}, nil)
+}
+
+// GetUserPolicy reads an inline policy of a user.
+//
+// !! caps: user-policy=read !!
+//
+// @UID
+// @PolicyName
+func (api *API) GetUserPolicy(conf UserPolicyConfig) (*UserPolicy, error) {
+	var ret struct {
+		UserName       string `xml:"GetUserPolicyResult>UserName"`
+		PolicyName     string `xml:"GetUserPolicyResult>PolicyName"`
+		PolicyDocument string `xml:"GetUserPolicyResult>PolicyDocument"`
+	}
+
+	if conf.UID == "" {
+		return nil, errors.New("UID field is required")
+	}
+	if conf.PolicyName == "" {
+		return nil, errors.New("PolicyName field is required")
+	}
+	if err := api.iamCall("GetUserPolicy", url.Values{
+		"UserName":   {conf.UID},
+		"PolicyName": {conf.PolicyName},
+	}, &ret); err != nil {
+		return nil, err
+	}
+	policy := &UserPolicy{
+		UserName:   ret.UserName,
+		PolicyName: ret.PolicyName,
+	}
+	if err := json.Unmarshal([]byte(ret.PolicyDocument), &policy.Policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ListUserPolicies lists the names of the inline policies of a user.
+//
+// !! caps: user-policy=read !!
+//
+// @UID
+func (api *API) ListUserPolicies(conf UserPolicyConfig) ([]string, error) {
+	var ret struct {
+		PolicyNames []string `xml:"ListUserPoliciesResult>PolicyNames>member"`
+	}
+
+	if conf.UID == "" {
+		return nil, errors.New("UID field is required")
+	}
+	if err := api.iamCall("ListUserPolicies", url.Values{"UserName": {conf.UID}}, &ret); err != nil {
+		return nil, err
+	}
+	return ret.PolicyNames, nil
+}
+
+// DeleteUserPolicy removes an inline policy of a user.
+//
+// !! caps: user-policy=write !!
+//
+// @UID
+// @PolicyName
+func (api *API) DeleteUserPolicy(conf UserPolicyConfig) error {
+	if conf.UID == "" {
+		return errors.New("UID field is required")
+	}
+	if conf.PolicyName == "" {
+		return errors.New("PolicyName field is required")
+	}
+	return api.iamCall("DeleteUserPolicy", url.Values{
+		"UserName":   {conf.UID},
+		"PolicyName": {conf.PolicyName},
+	}, nil)
+}
@@ -0,0 +1,118 @@
+package radosAPI
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPolicyDocument(t *testing.T) {
+	Convey("Testing PolicyDocument round trip", t, func() {
+		raw := `{"Version":"2012-10-17","Statement":[` +
+			`{"Effect":"Allow","Action":"s3:GetObject","Resource":["arn:aws:s3:::a/*","arn:aws:s3:::b/*"],` +
+			`"Condition":{"IpAddress":{"aws:SourceIp":"10.0.0.0/8"}}},` +
+			`{"Sid":"deny","Effect":"Deny","Action":["s3:Delete*","iam:PutUserPolicy"],"Resource":"*"}]}`
+
+		var doc PolicyDocument
+		err := json.Unmarshal([]byte(raw), &doc)
+		So(err, ShouldBeNil)
+		So(doc.Statement[0].Action, ShouldResemble, StringList{"s3:GetObject"})
+		So(doc.Statement[0].Condition["IpAddress"]["aws:SourceIp"], ShouldResemble, StringList{"10.0.0.0/8"})
+		So(doc.Statement[1].Action, ShouldResemble, StringList{"s3:Delete*", "iam:PutUserPolicy"})
+		So(doc.Validate(), ShouldBeNil)
+
+		js, err := json.Marshal(doc)
+		So(err, ShouldBeNil)
+		So(string(js), ShouldEqual, raw)
+	})
+
+	Convey("Testing PolicyDocument conditions with numbers and booleans", t, func() {
+		var doc PolicyDocument
+		err := json.Unmarshal([]byte(`{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"s3:*","Resource":"*",`+
+			`"Condition":{"Bool":{"aws:SecureTransport":false},"NumericLessThan":{"s3:max-keys":[10,"20.5"]}}}]}`), &doc)
+		So(err, ShouldBeNil)
+		So(doc.Statement[0].Condition["Bool"]["aws:SecureTransport"], ShouldResemble, StringList{"false"})
+		So(doc.Statement[0].Condition["NumericLessThan"]["s3:max-keys"], ShouldResemble, StringList{"10", "20.5"})
+
+		err = json.Unmarshal([]byte(`{"Statement":[{"Effect":"Allow","Action":{"s3":"GetObject"}}]}`), &doc)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing PolicyDocument validation", t, func() {
+		So(PolicyDocument{}.Validate(), ShouldNotBeNil)
+		So(PolicyDocument{Statement: []Statement{{Effect: "Maybe", Action: StringList{"s3:GetObject"}}}}.Validate(), ShouldNotBeNil)
+		So(PolicyDocument{Statement: []Statement{{Effect: "Allow"}}}.Validate(), ShouldNotBeNil)
+		So(PolicyDocument{Statement: []Statement{{Effect: "Allow", Action: StringList{"s3:GetObjects"}}}}.Validate(), ShouldNotBeNil)
+		So(PolicyDocument{Statement: []Statement{{Effect: "Allow", Action: StringList{"ec2:*"}}}}.Validate(), ShouldNotBeNil)
+		So(PolicyDocument{Statement: []Statement{{Effect: "Allow", Action: StringList{"*"}}}}.Validate(), ShouldBeNil)
+		So(PolicyDocument{Statement: []Statement{{Effect: "Allow", Action: StringList{"S3:getobject"}}}}.Validate(), ShouldBeNil)
+		So(PolicyDocument{Statement: []Statement{{Effect: "Allow", Action: StringList{"s3:List?ucket"}}}}.Validate(), ShouldBeNil)
+	})
+}
+
+func TestUserPolicy(t *testing.T) {
+	sent := ""
+	api, server := newTestAPI(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		switch query.Get("Action") {
+		case "PutUserPolicy":
+			sent = query.Get("PolicyDocument")
+		case "GetUserPolicy":
+			fmt.Fprintf(w, `<GetUserPolicyResponse><GetUserPolicyResult><UserName>%s</UserName>`+
+				`<PolicyName>%s</PolicyName><PolicyDocument>%s</PolicyDocument>`+
+				`</GetUserPolicyResult></GetUserPolicyResponse>`, query.Get("UserName"), query.Get("PolicyName"), sent)
+		}
+	})
+	defer server.Close()
+
+	Convey("Testing PutUserPolicy without resource", t, func() {
+		err := api.PutUserPolicy(UserPolicyConfig{
+			UID:        "UnitTest",
+			PolicyName: "read",
+			Policy: &PolicyDocument{
+				Version:   "2012-10-17",
+				Statement: []Statement{{Effect: "Allow", Action: StringList{"s3:GetObject"}}},
+			},
+		})
+		So(err, ShouldNotBeNil)
+		So(sent, ShouldEqual, "")
+	})
+
+	Convey("Testing Put and Get UserPolicy", t, func() {
+		policy := &PolicyDocument{
+			Version:   "2012-10-17",
+			Statement: []Statement{{Effect: "Allow", Action: StringList{"s3:GetObject"}, Resource: StringList{"*"}}},
+		}
+		err := api.PutUserPolicy(UserPolicyConfig{
+			UID:        "UnitTest",
+			PolicyName: "read",
+			Policy:     policy,
+		})
+		So(err, ShouldBeNil)
+		So(sent, ShouldEqual, `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":"s3:GetObject","Resource":"*"}]}`)
+
+		ret, err := api.GetUserPolicy(UserPolicyConfig{
+			UID:        "UnitTest",
+			PolicyName: "read",
+		})
+		So(err, ShouldBeNil)
+		So(ret.UserName, ShouldEqual, "UnitTest")
+		So(ret.PolicyName, ShouldEqual, "read")
+		So(ret.Policy, ShouldResemble, *policy)
+	})
+
+	Convey("Testing GetUserPolicy with a stored numeric and boolean condition", t, func() {
+		sent = `{"Version":"2012-10-17","Statement":[{"Effect":"Deny","Action":"s3:ListBucket","Resource":"*",` +
+			`"Condition":{"Bool":{"aws:SecureTransport":false},"NumericGreaterThan":{"s3:max-keys":1000}}}]}`
+		ret, err := api.GetUserPolicy(UserPolicyConfig{
+			UID:        "UnitTest",
+			PolicyName: "secure",
+		})
+		So(err, ShouldBeNil)
+		So(ret.Policy.Statement[0].Condition["Bool"]["aws:SecureTransport"], ShouldResemble, StringList{"false"})
+		So(ret.Policy.Statement[0].Condition["NumericGreaterThan"]["s3:max-keys"], ShouldResemble, StringList{"1000"})
+	})
+}
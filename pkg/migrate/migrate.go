@@ -0,0 +1,338 @@
+// Package migrate moves a user, its buckets and their objects from a cluster to another
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/QuentinPerez/go-radosgw/pkg/api"
+	minio "github.com/minio/minio-go"
+)
+
+// checkpointEvery is the number of objects copied between two writes of the checkpoint
+const checkpointEvery = 100
+
+// Config migration request
+type Config struct {
+	UID          string            // The user to migrate
+	Source       *radosAPI.API     // The admin API of the source cluster
+	Target       *radosAPI.API     // The admin API of the target cluster
+	SourceS3     string            // The S3 endpoint of the source cluster, host[:port]
+	TargetS3     string            // The S3 endpoint of the target cluster, host[:port]
+	Secure       bool              // Use https to reach the S3 endpoints
+	Transport    http.RoundTripper // The transport of the S3 clients, http.DefaultTransport if nil
+	Checkpoint   string            // The file recording the copied objects, a migration started again with it resumes
+	RemoveSource bool              // Remove the user and its data from the source once every bucket is verified
+	Progress     func(Progress)    // Called after each copied object
+}
+
+// Progress reports the copy of an object
+type Progress struct {
+	Bucket string
+	Object string
+	Copied int64 // The objects of Bucket copied so far, including the previous runs
+	Total  int64 // The objects of Bucket according to the source stats
+}
+
+// BucketReport represents the migration of a bucket
+type BucketReport struct {
+	Bucket        string
+	Copied        int64 // The objects copied by this run
+	SourceObjects int64 // The objects counted by the source stats
+	TargetObjects int64 // The objects counted by the target stats
+}
+
+// Report represents the outcome of Run
+type Report struct {
+	UID           string
+	Buckets       []BucketReport
+	SourceRemoved bool
+}
+
+// checkpoint is the content of Config.Checkpoint
+type checkpoint struct {
+	UID     string                       `json:"uid"`
+	Buckets map[string]*bucketCheckpoint `json:"buckets"`
+}
+
+type bucketCheckpoint struct {
+	Marker string `json:"marker"` // The last object copied, objects are copied in lexical order
+	Copied int64  `json:"copied"`
+	Done   bool   `json:"done"`
+}
+
+type migration struct {
+	conf       Config
+	checkpoint *checkpoint
+	source     minio.Core
+	target     *minio.Client
+}
+
+// Run migrates the user conf.UID from conf.Source to conf.Target:
+// the user is recreated on the target with its keys, subusers, caps and quotas,
+// then its buckets are created on the target with its own key, so it owns them,
+// and their objects are copied through S3.
+// Each bucket is verified by comparing the number of objects of the source and
+// target stats, versioned buckets only have their current objects copied and
+// fail the verification if they hold older versions.
+// The source is removed only if conf.RemoveSource is set and every bucket is verified.
+func Run(conf Config) (*Report, error) {
+	switch {
+	case conf.UID == "":
+		return nil, errors.New("UID field is required")
+	case conf.Source == nil || conf.Target == nil:
+		return nil, errors.New("Source and Target fields are required")
+	case conf.SourceS3 == "" || conf.TargetS3 == "":
+		return nil, errors.New("SourceS3 and TargetS3 fields are required")
+	}
+	cp, err := loadCheckpoint(conf.Checkpoint, conf.UID)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := conf.Source.ExportUsers(radosAPI.ExportConfig{UIDs: []string{conf.UID}})
+	if err != nil {
+		return nil, err
+	}
+	imported, err := conf.Target.ImportUsers(archive, radosAPI.ImportConfig{Conflict: radosAPI.ConflictOverwrite})
+	if err != nil {
+		return nil, err
+	}
+	if err = imported.Failed[conf.UID]; err != nil {
+		return nil, fmt.Errorf("import %s: %v", conf.UID, err)
+	}
+
+	m := &migration{conf: conf, checkpoint: cp}
+	if err = m.connect(archive.Users[0].User); err != nil {
+		return nil, err
+	}
+	stats, err := conf.Source.ListBucketStats(radosAPI.BucketConfig{UID: conf.UID})
+	if err != nil {
+		return nil, err
+	}
+	ret := &Report{UID: conf.UID}
+	for _, stat := range stats {
+		bucket, err := m.migrateBucket(stat)
+		if err != nil {
+			return ret, err
+		}
+		ret.Buckets = append(ret.Buckets, *bucket)
+	}
+
+	if !conf.RemoveSource {
+		return ret, nil
+	}
+	if err = conf.Source.RemoveUser(radosAPI.UserConfig{UID: conf.UID, PurgeData: true}); err != nil {
+		return ret, err
+	}
+	ret.SourceRemoved = true
+	if conf.Checkpoint != "" {
+		if err = os.Remove(conf.Checkpoint); err != nil && !os.IsNotExist(err) {
+			return ret, err
+		}
+	}
+	return ret, nil
+}
+
+// connect creates the S3 clients with the first S3 key of user, which exists on both clusters once imported
+func (m *migration) connect(user radosAPI.User) error {
+	var accessKey, secretKey string
+
+	for _, key := range user.Keys {
+		if key.User == user.UserID {
+			accessKey, secretKey = key.AccessKey, key.SecretKey
+			break
+		}
+	}
+	if accessKey == "" {
+		return fmt.Errorf("%s has no S3 key", user.UserID)
+	}
+	source, err := minio.New(m.conf.SourceS3, accessKey, secretKey, m.conf.Secure)
+	if err != nil {
+		return err
+	}
+	target, err := minio.New(m.conf.TargetS3, accessKey, secretKey, m.conf.Secure)
+	if err != nil {
+		return err
+	}
+	if m.conf.Transport != nil {
+		source.SetCustomTransport(m.conf.Transport)
+		target.SetCustomTransport(m.conf.Transport)
+	}
+	m.source = minio.Core{Client: source}
+	m.target = target
+	return nil
+}
+
+// migrateBucket creates the bucket on the target, copies the objects not copied yet and verifies the bucket
+func (m *migration) migrateBucket(stat radosAPI.Stats) (*BucketReport, error) {
+	name := stat.Bucket
+	ret := &BucketReport{Bucket: name}
+	cp := m.checkpoint.bucket(name)
+
+	if !cp.Done {
+		if err := m.createBucket(name); err != nil {
+			return nil, err
+		}
+		total := objects(stat)
+		token := ""
+		for {
+			startAfter := ""
+			if token == "" {
+				startAfter = cp.Marker
+			}
+			list, err := m.source.ListObjectsV2(name, "", token, false, "", 1000, startAfter)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+			for _, object := range list.Contents {
+				if err = m.copyObject(name, object.Key); err != nil {
+					// keep the objects copied so far for the next run
+					if errSave := m.save(); errSave != nil {
+						return nil, fmt.Errorf("%s/%s: %v, and the checkpoint couldn't be saved: %v", name, object.Key, err, errSave)
+					}
+					return nil, fmt.Errorf("%s/%s: %v", name, object.Key, err)
+				}
+				cp.Marker = object.Key
+				cp.Copied++
+				ret.Copied++
+				if ret.Copied%checkpointEvery == 0 {
+					if err = m.save(); err != nil {
+						return nil, err
+					}
+				}
+				if m.conf.Progress != nil {
+					m.conf.Progress(Progress{Bucket: name, Object: object.Key, Copied: cp.Copied, Total: total})
+				}
+			}
+			if !list.IsTruncated {
+				break
+			}
+			token = list.NextContinuationToken
+		}
+		cp.Done = true
+		if err := m.save(); err != nil {
+			return nil, err
+		}
+	}
+
+	source, err := m.conf.Source.ListBucketStats(radosAPI.BucketConfig{Bucket: name})
+	if err != nil {
+		return nil, err
+	}
+	target, err := m.conf.Target.ListBucketStats(radosAPI.BucketConfig{Bucket: name})
+	if err != nil {
+		return nil, err
+	}
+	if len(source) != 1 || len(target) != 1 {
+		return nil, fmt.Errorf("%s: unexpected stats", name)
+	}
+	ret.SourceObjects, ret.TargetObjects = objects(source[0]), objects(target[0])
+	if ret.SourceObjects != ret.TargetObjects {
+		return nil, fmt.Errorf("%s: %d objects on the source, %d on the target", name, ret.SourceObjects, ret.TargetObjects)
+	}
+	return ret, nil
+}
+
+// createBucket creates the bucket on the target, or checks the user owns it if it exists
+func (m *migration) createBucket(name string) error {
+	exists, err := m.target.BucketExists(name)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	if !exists {
+		if err = m.target.MakeBucket(name, ""); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		return nil
+	}
+	stats, err := m.conf.Target.ListBucketStats(radosAPI.BucketConfig{Bucket: name})
+	if err != nil {
+		return err
+	}
+	for _, stat := range stats {
+		if stat.Owner != m.conf.UID {
+			return fmt.Errorf("%s: the bucket belongs to %s on the target", name, stat.Owner)
+		}
+	}
+	return nil
+}
+
+// copyObject copies an object with its content type and user metadata
+func (m *migration) copyObject(bucket, key string) error {
+	object, info, err := m.source.GetObject(bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+	opts := minio.PutObjectOptions{
+		ContentType:  info.ContentType,
+		UserMetadata: map[string]string{},
+	}
+	for header := range info.Metadata {
+		if strings.HasPrefix(strings.ToLower(header), "x-amz-meta-") {
+			opts.UserMetadata[header[len("x-amz-meta-"):]] = info.Metadata.Get(header)
+		}
+	}
+	_, err = m.target.PutObject(bucket, key, object, info.Size, opts)
+	return err
+}
+
+// objects returns the number of objects counted by stat
+func objects(stat radosAPI.Stats) int64 {
+	return stat.Usage[radosAPI.UsageMain].NumObjects
+}
+
+// loadCheckpoint reads the checkpoint of uid from path, an empty one is returned if it doesn't exist
+func loadCheckpoint(path, uid string) (*checkpoint, error) {
+	ret := &checkpoint{UID: uid, Buckets: map[string]*bucketCheckpoint{}}
+
+	if path == "" {
+		return ret, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ret, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if ret.UID != uid {
+		return nil, fmt.Errorf("%s is the checkpoint of %s", path, ret.UID)
+	}
+	if ret.Buckets == nil {
+		ret.Buckets = map[string]*bucketCheckpoint{}
+	}
+	return ret, nil
+}
+
+func (cp *checkpoint) bucket(name string) *bucketCheckpoint {
+	if cp.Buckets[name] == nil {
+		cp.Buckets[name] = &bucketCheckpoint{}
+	}
+	return cp.Buckets[name]
+}
+
+// save writes the checkpoint, through a temporary file so it is never truncated
+func (m *migration) save() error {
+	if m.conf.Checkpoint == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.checkpoint, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := m.conf.Checkpoint + ".tmp"
+	if err = ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, m.conf.Checkpoint)
+}
@@ -0,0 +1,293 @@
+package migrate
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/QuentinPerez/go-radosgw/pkg/api"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// fakeCluster serves the admin API and a path style S3 API, keeping everything in memory
+type fakeCluster struct {
+	lock    sync.Mutex
+	users   map[string]*radosAPI.User
+	buckets map[string]*fakeBucket
+	failPut string // An object whose next upload fails
+	puts    int
+}
+
+type fakeBucket struct {
+	owner   string
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	meta        string
+}
+
+type fakeListResult struct {
+	XMLName               xml.Name `xml:"ListBucketResult"`
+	Name                  string
+	IsTruncated           bool
+	NextContinuationToken string
+	Contents              []fakeListEntry
+}
+
+type fakeListEntry struct {
+	Key  string
+	Size int
+}
+
+func newFakeCluster() *fakeCluster {
+	return &fakeCluster{users: map[string]*radosAPI.User{}, buckets: map[string]*fakeBucket{}}
+}
+
+func (f *fakeCluster) handler(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, "/admin/") {
+		f.admin(w, r)
+		return
+	}
+	query := r.URL.Query()
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	bucket := f.buckets[path[0]]
+	if len(path) == 1 || path[1] == "" {
+		_, location := query["location"]
+		switch {
+		case location:
+			w.Write([]byte(`<LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`))
+		case r.Method == "PUT":
+			credential := strings.SplitN(r.Header.Get("Authorization"), "Credential=", 2)[1]
+			accessKey := strings.SplitN(credential, "/", 2)[0]
+			for uid, user := range f.users {
+				if user.Keys[0].AccessKey == accessKey {
+					f.buckets[path[0]] = &fakeBucket{owner: uid, objects: map[string]fakeObject{}}
+				}
+			}
+		case bucket == nil:
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "GET":
+			keys := []string{}
+			after := query.Get("start-after")
+			if token := query.Get("continuation-token"); token != "" {
+				after = token
+			}
+			for key := range bucket.objects {
+				if key > after {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			ret := fakeListResult{Name: path[0]}
+			if len(keys) > 2 {
+				keys = keys[:2]
+				ret.IsTruncated, ret.NextContinuationToken = true, keys[1]
+			}
+			for _, key := range keys {
+				ret.Contents = append(ret.Contents, fakeListEntry{Key: key, Size: len(bucket.objects[key].data)})
+			}
+			xml.NewEncoder(w).Encode(ret)
+		}
+		return
+	}
+	switch r.Method {
+	case "GET":
+		object := bucket.objects[path[1]]
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("X-Amz-Meta-Owner", object.meta)
+		w.Write(object.data)
+	case "PUT":
+		if path[1] == f.failPut {
+			f.failPut = ""
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		bucket.objects[path[1]] = fakeObject{
+			data:        data,
+			contentType: r.Header.Get("Content-Type"),
+			meta:        r.Header.Get("X-Amz-Meta-Owner"),
+		}
+		f.puts++
+		w.Header().Set("ETag", `"etag"`)
+	}
+}
+
+func (f *fakeCluster) admin(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	sub := strings.SplitN(r.URL.RawQuery, "&", 2)[0]
+	if strings.Contains(sub, "=") {
+		sub = ""
+	}
+	if r.URL.Path == "/admin/bucket" {
+		stats := []radosAPI.Stats{}
+		for name, bucket := range f.buckets {
+			if (query.Get("uid") == "" || query.Get("uid") == bucket.owner) && (query.Get("bucket") == "" || query.Get("bucket") == name) {
+				stats = append(stats, radosAPI.Stats{
+					Bucket: name,
+					Owner:  bucket.owner,
+					Usage:  map[string]radosAPI.StorageStats{radosAPI.UsageMain: {NumObjects: int64(len(bucket.objects))}},
+				})
+			}
+		}
+		json.NewEncoder(w).Encode(stats)
+		return
+	}
+	uid := query.Get("uid")
+	user := f.users[uid]
+	if user == nil && !(r.Method == "PUT" && sub == "") {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"Code":"NoSuchUser"}`))
+		return
+	}
+	switch r.Method + " " + sub {
+	case "GET quota":
+		w.Write([]byte(`{}`))
+		return
+	case "PUT ":
+		user = &radosAPI.User{UserID: uid, DisplayName: query.Get("display-name")}
+		user.Keys = radosAPI.KeysDefinition{{AccessKey: query.Get("access-key"), SecretKey: query.Get("secret-key"), User: uid}}
+		f.users[uid] = user
+	case "PUT key", "PUT quota":
+		w.Write([]byte(`[]`))
+		return
+	case "DELETE ":
+		for name, bucket := range f.buckets {
+			if bucket.owner == uid {
+				delete(f.buckets, name)
+			}
+		}
+		delete(f.users, uid)
+		return
+	}
+	json.NewEncoder(w).Encode(user)
+}
+
+func TestRun(t *testing.T) {
+	source := newFakeCluster()
+	source.users["john"] = &radosAPI.User{
+		UserID:      "john",
+		DisplayName: "John",
+		Keys:        radosAPI.KeysDefinition{{AccessKey: "AK1", SecretKey: "SK1", User: "john"}},
+	}
+	source.buckets["photos"] = &fakeBucket{owner: "john", objects: map[string]fakeObject{}}
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		source.buckets["photos"].objects[key] = fakeObject{data: []byte("data of " + key), contentType: "image/png", meta: "john"}
+	}
+	source.buckets["empty"] = &fakeBucket{owner: "john", objects: map[string]fakeObject{}}
+	sourceServer := httptest.NewTLSServer(http.HandlerFunc(source.handler))
+	defer sourceServer.Close()
+	target := newFakeCluster()
+	targetServer := httptest.NewTLSServer(http.HandlerFunc(target.handler))
+	defer targetServer.Close()
+
+	sourceAPI, err := radosAPI.NewWithClient(sourceServer.Client(), sourceServer.URL, "admin", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	targetAPI, err := radosAPI.NewWithClient(targetServer.Client(), targetServer.URL, "admin", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "migrate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	conf := Config{
+		UID:        "john",
+		Source:     sourceAPI,
+		Target:     targetAPI,
+		SourceS3:   sourceServer.Listener.Addr().String(),
+		TargetS3:   targetServer.Listener.Addr().String(),
+		Secure:     true,
+		Transport:  sourceServer.Client().Transport,
+		Checkpoint: filepath.Join(dir, "checkpoint.json"),
+	}
+
+	Convey("Testing a migration", t, func() {
+		_, err := Run(Config{UID: "john"})
+		So(err, ShouldNotBeNil)
+
+		// without the empty bucket, whose checkpoint would be saved first
+		empty := source.buckets["empty"]
+		delete(source.buckets, "empty")
+		unsaved := conf
+		unsaved.Checkpoint = filepath.Join(dir, "missing", "checkpoint.json")
+		target.failPut = "a"
+		_, err = Run(unsaved)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "the checkpoint couldn't be saved")
+		So(target.buckets["photos"].objects, ShouldBeEmpty)
+		source.buckets["empty"] = empty
+
+		target.failPut = "d"
+		_, err = Run(conf)
+		So(err, ShouldNotBeNil)
+		So(target.users["john"].Keys, ShouldResemble, source.users["john"].Keys)
+		So(target.buckets["photos"].owner, ShouldEqual, "john")
+		So(target.buckets["photos"].objects, ShouldHaveLength, 3)
+
+		progress := []Progress{}
+		conf.Progress = func(p Progress) {
+			progress = append(progress, p)
+		}
+		report, err := Run(conf)
+		So(err, ShouldBeNil)
+		So(target.puts, ShouldEqual, 5)
+		So(progress, ShouldResemble, []Progress{
+			{Bucket: "photos", Object: "d", Copied: 4, Total: 5},
+			{Bucket: "photos", Object: "e", Copied: 5, Total: 5},
+		})
+		sort.Slice(report.Buckets, func(i, j int) bool {
+			return report.Buckets[i].Bucket < report.Buckets[j].Bucket
+		})
+		So(report.Buckets, ShouldResemble, []BucketReport{
+			{Bucket: "empty"},
+			{Bucket: "photos", Copied: 2, SourceObjects: 5, TargetObjects: 5},
+		})
+		So(target.buckets["photos"].objects, ShouldResemble, source.buckets["photos"].objects)
+		So(report.SourceRemoved, ShouldBeFalse)
+
+		target.buckets["photos"].objects["f"] = fakeObject{}
+		_, err = Run(conf)
+		So(err, ShouldNotBeNil)
+		delete(target.buckets["photos"].objects, "f")
+
+		conf.RemoveSource = true
+		report, err = Run(conf)
+		So(err, ShouldBeNil)
+		So(target.puts, ShouldEqual, 5)
+		So(report.SourceRemoved, ShouldBeTrue)
+		So(source.users, ShouldBeEmpty)
+		So(source.buckets, ShouldBeEmpty)
+		_, err = os.Stat(conf.Checkpoint)
+		So(os.IsNotExist(err), ShouldBeTrue)
+	})
+
+	Convey("Testing a checkpoint of another user", t, func() {
+		path := filepath.Join(dir, "other.json")
+		So(ioutil.WriteFile(path, []byte(`{"uid":"jane"}`), 0600), ShouldBeNil)
+		_, err := loadCheckpoint(path, "john")
+		So(err, ShouldNotBeNil)
+	})
+}
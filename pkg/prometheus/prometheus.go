@@ -0,0 +1,71 @@
+// Package prometheus records the requests sent by a radosAPI.API as Prometheus metrics
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements radosAPI.Metrics with Prometheus collectors
+type Metrics struct {
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	inFlight *prometheus.GaugeVec
+}
+
+// New returns Metrics registered in registerer, with names prefixed by namespace
+func New(namespace string, registerer prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "radosgw_client",
+			Name:      "requests_total",
+			Help:      "Number of requests sent to the gateway.",
+		}, []string{"operation", "status", "code"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "radosgw_client",
+			Name:      "request_errors_total",
+			Help:      "Number of failed requests.",
+		}, []string{"operation", "status", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "radosgw_client",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of the requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"operation", "status"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "radosgw_client",
+			Name:      "requests_in_flight",
+			Help:      "Number of requests waiting for a response.",
+		}, []string{"operation"}),
+	}
+	for _, collector := range []prometheus.Collector{m.requests, m.errors, m.latency, m.inFlight} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// RequestStarted implements radosAPI.Metrics
+func (m *Metrics) RequestStarted(operation string) {
+	m.inFlight.WithLabelValues(operation).Inc()
+}
+
+// RequestDone implements radosAPI.Metrics
+func (m *Metrics) RequestDone(operation string, statusCode int, code string, latency time.Duration, err error) {
+	status := strconv.Itoa(statusCode)
+
+	m.inFlight.WithLabelValues(operation).Dec()
+	m.requests.WithLabelValues(operation, status, code).Inc()
+	m.latency.WithLabelValues(operation, status).Observe(latency.Seconds())
+	if err != nil {
+		m.errors.WithLabelValues(operation, status, code).Inc()
+	}
+}
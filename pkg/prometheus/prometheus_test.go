@@ -0,0 +1,77 @@
+package prometheus
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	radosAPI "github.com/QuentinPerez/go-radosgw/pkg/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("uid") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"Code":"NoSuchUser"}`)
+			return
+		}
+		fmt.Fprint(w, `{"user_id":"john"}`)
+	}))
+	defer server.Close()
+	api, err := radosAPI.New(server.URL, "access", "secret")
+	if err != nil {
+		panic(err)
+	}
+	registry := prometheus.NewRegistry()
+	metrics, err := New("test", registry)
+	if err != nil {
+		panic(err)
+	}
+	api.SetMetrics(metrics)
+
+	Convey("Testing requests are counted", t, func() {
+		_, err := api.GetUser("john")
+		So(err, ShouldBeNil)
+		_, err = api.GetUser("missing")
+		So(err, ShouldNotBeNil)
+
+		So(testutil.ToFloat64(metrics.requests.WithLabelValues("GetUser", "200", "")), ShouldEqual, 1)
+		So(testutil.ToFloat64(metrics.requests.WithLabelValues("GetUser", "404", "NoSuchUser")), ShouldEqual, 1)
+		So(testutil.ToFloat64(metrics.errors.WithLabelValues("GetUser", "404", "NoSuchUser")), ShouldEqual, 1)
+		So(testutil.ToFloat64(metrics.inFlight.WithLabelValues("GetUser")), ShouldEqual, 0)
+		So(testutil.CollectAndCount(metrics.latency), ShouldEqual, 2)
+	})
+
+	Convey("Testing metrics are registered once", t, func() {
+		_, err := New("test", registry)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Testing the operation and status labels are gathered by the registry", t, func() {
+		registry := prometheus.NewRegistry()
+		metrics, err := New("labels", registry)
+		So(err, ShouldBeNil)
+		api.SetMetrics(metrics)
+
+		_, err = api.CreateUser(radosAPI.UserConfig{UID: "john", DisplayName: "John"})
+		So(err, ShouldBeNil)
+		_, err = api.GetUser("missing")
+		So(err, ShouldNotBeNil)
+		_, err = api.GetUserRateLimit(radosAPI.RateLimitConfig{UID: "john"})
+		So(err, ShouldBeNil)
+
+		expected := `
+# HELP labels_radosgw_client_requests_total Number of requests sent to the gateway.
+# TYPE labels_radosgw_client_requests_total counter
+labels_radosgw_client_requests_total{code="",operation="CreateUser",status="200"} 1
+labels_radosgw_client_requests_total{code="",operation="GetUserRateLimit",status="200"} 1
+labels_radosgw_client_requests_total{code="NoSuchUser",operation="GetUser",status="404"} 1
+`
+		So(testutil.GatherAndCompare(registry, strings.NewReader(expected), "labels_radosgw_client_requests_total"), ShouldBeNil)
+	})
+}